@@ -1,7 +1,8 @@
 package config
 
 import (
-	"log/slog"
+	"fmt"
+	"strings"
 	"time"
 	"xzdp/pkg/logger"
 
@@ -9,18 +10,25 @@ import (
 	"github.com/spf13/viper"
 )
 
+// 只在启动时读取的配置，InitConfig之后不再修改，热加载时新的值需要重启才能生效
+// 可以热更新的配置（缓存有效期、防刷、积分等）没有全局变量，通过 Current() 读取
 var (
 	ServerOption *ServerSetting
 	MysqlOption  *MysqlSetting
 	LogOption    *logger.LogSetting
 	JwtOption    *JWTSetting
+	TraceOption  *TracingSetting
 )
 
 type ServerSetting struct {
-	RunMode      string
-	HttpPort     string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	RunMode         string
+	HttpPort        string
+	ReadTimeout     time.Duration //读取整个请求（包括请求体）的超时时间
+	WriteTimeout    time.Duration //从读完请求头到写完响应的超时时间
+	IdleTimeout     time.Duration //keep-alive连接的空闲超时时间
+	ShutdownTimeout time.Duration //退出时等待处理中的请求和后台任务结束的最长时间
+	ShutdownDelay   time.Duration //收到退出信号后先报告未就绪，等待这段时间再停止接受请求
+	MetricsAddr     string        //Prometheus指标单独监听的地址，只在内网开放，为空时不提供指标
 }
 
 type MysqlSetting struct {
@@ -30,22 +38,180 @@ type MysqlSetting struct {
 	DbName       string
 	MaxIdleConns int
 	MaxOpenConns int
+	//只读从库的地址，账号、密码和库名与主库相同，为空时读写都走主库
+	Replicas             []string
+	ReplicaCheckInterval time.Duration //从库健康检查的间隔，不可用的从库不再分配读请求
 }
 
 type JWTSetting struct {
-	Secret string
-	Issuer string
-	Expire time.Duration
+	Secret        string //没有配置Keys时使用的HS256密钥
+	SigningKey    string //签发令牌使用的密钥kid
+	Keys          []JWTKey
+	Issuer        string
+	Expire        time.Duration //access token有效期，尽量短
+	RefreshExpire time.Duration //refresh token有效期，超过这个时间没有刷新就需要重新登录
+	MaxSessions   int           //同一用户同时在线的设备数上限，超过时踢掉最早登录的设备，0表示不限制
+}
+
+// JWT密钥，Keys中的密钥都可以用来验证令牌，轮换时新旧密钥同时配置
+type JWTKey struct {
+	Kid            string
+	Alg            string //HS256、RS256、ES256、EdDSA
+	Secret         string //HS256的密钥
+	PrivateKeyFile string //非对称算法的PEM私钥文件
+	PublicKeyFile  string //只有公钥时只能验证，不能签发
 }
 
 var (
 	RedisOption *RedisSetting
+	SMSOption   *SMSSetting
+	RBACOption  *RBACSetting
+	OAuthOption *OAuthSetting
 )
 
 type RedisSetting struct {
-	Host     string
-	Password string
-	PoolSize int
+	Mode             string   //standalone（默认）、sentinel、cluster
+	Host             string   //standalone模式的地址
+	Addrs            []string //sentinel模式是哨兵的地址，cluster模式是集群节点的地址
+	MasterName       string   //sentinel模式的主节点名称
+	SentinelPassword string   //哨兵的密码
+	Username         string   //ACL用户名
+	Password         string
+	DB               int //库编号，cluster模式只能用0
+	PoolSize         int //cluster模式是每个节点的连接数
+	MinIdleConns     int
+	DialTimeout      time.Duration
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	PoolTimeout      time.Duration //连接池没有空闲连接时的等待时间
+	MaxRetries       int           //命令失败后的重试次数，-1表示不重试
+	MinRetryBackoff  time.Duration //重试的退避时间范围
+	MaxRetryBackoff  time.Duration
+	TLS              RedisTLSSetting
+}
+
+type RedisTLSSetting struct {
+	Enable             bool
+	CAFile             string //服务端证书的CA，为空时使用系统的CA
+	CertFile           string //双向认证时客户端的证书和私钥
+	KeyFile            string
+	ServerName         string //校验证书时使用的域名，为空时使用连接的地址
+	InsecureSkipVerify bool   //不校验服务端证书，只用于测试环境
+}
+
+// 热门博客排行配置
+// 热度 = log10(max(点赞数*LikeWeight + 评论数*CommentWeight, 1)) + (发布时间 - 纪元) / DecayHours
+// 发布时间越晚基础分越高，所以老博客需要更多的互动才能排在前面，而且分数不用随时间重算
+type HotBlogSetting struct {
+	LikeWeight    float64
+	CommentWeight float64
+	DecayHours    float64 //每过DecayHours小时，新博客需要的互动量就是旧博客的1/10
+	RankSize      int64   //排行榜最多保留的博客数量
+	PageSize      int64
+}
+
+// 缓存有效期，0表示不过期
+type CacheSetting struct {
+	Shop         time.Duration //商户详情和按类型分页的商户列表
+	ShopType     time.Duration //商户类型列表
+	Voucher      time.Duration //商户的优惠券列表
+	UserInfo     time.Duration //用户资料
+	Blog         time.Duration //博客详情
+	SeckillStock time.Duration //秒杀库存，过期后从数据库重新加载
+}
+
+// 签到配置
+type SignSetting struct {
+	BackupQuota     int           //每个月可以补签的次数
+	ArchiveInterval time.Duration //把Redis中的签到记录归档到tb_sign的间隔
+}
+
+// 积分和会员等级配置
+type CreditSetting struct {
+	Sign   int32         //每日签到获得的积分
+	Blog   int32         //发布博客获得的积分
+	Liked  int32         //博客每被一个用户点赞获得的积分
+	Streak map[int]int32 //连续签到达到n天时额外奖励的积分
+	Levels []uint32      //升到1~9级需要的累计积分，必须递增
+}
+
+// 密码哈希和登录失败锁定配置
+// argon2id参数调大后，旧密码会在用户下次用密码登录成功时按新参数重新哈希
+type PasswordSetting struct {
+	Memory       uint32        //argon2id内存开销，单位KB
+	Iterations   uint32        //argon2id迭代次数
+	Parallelism  uint8         //argon2id并行度
+	MaxFailures  int           //连续输错密码的次数上限，达到后锁定账号
+	LockDuration time.Duration //锁定时长，同时也是失败次数的统计窗口
+}
+
+// 账号注销配置
+type AccountSetting struct {
+	GracePeriod   time.Duration //申请注销后的冷静期，期间重新登录会撤销申请
+	PurgeInterval time.Duration //检查冷静期已经结束的注销申请的间隔
+}
+
+// 链路追踪配置
+type TracingSetting struct {
+	Exporter    string  //none：不导出；stdout：打印到标准输出；file：写入File；otlp：通过OTLP/HTTP发送到Endpoint
+	File        string  //Exporter为file时写入的文件
+	Endpoint    string  //OTLP/HTTP地址，例如localhost:4318
+	Insecure    bool    //OTLP不使用TLS
+	ServiceName string  //服务名
+	SampleRatio float64 //采样比例，0~1；上游已经决定采样的请求跟随上游
+}
+
+// 短信配置
+type SMSSetting struct {
+	Provider  string            //log：只打印日志；file：追加写入文件；http：调用短信网关
+	File      string            //Provider为file时写入的文件
+	URL       string            //Provider为http时短信网关的地址
+	APIKey    string            //Provider为http时放在Authorization请求头中的密钥
+	Timeout   time.Duration     //Provider为http时的请求超时
+	Templates map[string]string //短信模板，{name}会被替换为对应的参数
+}
+
+// 第三方登录配置
+type OAuthSetting struct {
+	Providers []OAuthProviderSetting
+	StateTTL  time.Duration //跳转到身份提供方后多久内必须完成登录
+	BindTTL   time.Duration //第一次登录时绑定手机号的有效期
+}
+
+// 身份提供方配置，所有地址都可以配置，测试时可以指向 oidctest.Server
+type OAuthProviderSetting struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	Issuer       string
+	RedirectURL  string //身份提供方回调的地址，必须与在身份提供方登记的一致
+	Scopes       []string
+}
+
+// 权限配置
+type RBACSetting struct {
+	Admins []uint64 //启动时授予管理员角色的用户id，用于初始化第一个管理员
+}
+
+// 发送验证码和登录的防刷配置
+type AntiAbuseSetting struct {
+	PhoneWindows      []RateLimitWindow //同一手机号发送验证码的滑动窗口限制
+	IPWindows         []RateLimitWindow //同一IP发送验证码的滑动窗口限制
+	LoginIPWindows    []RateLimitWindow //同一IP登录的滑动窗口限制
+	LoginPhoneWindows []RateLimitWindow //同一手机号登录的滑动窗口限制
+	MaxCodeAttempts   int               //同一个验证码最多能输错的次数，达到后验证码失效
+	BanThreshold      int               //BanWindow内被限流或者验证码失效的次数达到后，加入封禁名单
+	BanWindow         time.Duration
+	BanDuration       time.Duration //封禁时长
+}
+
+// 滑动窗口限流：在Duration时间内最多允许Limit次
+type RateLimitWindow struct {
+	Duration time.Duration
+	Limit    int64
 }
 
 // viper的使用
@@ -57,80 +223,65 @@ type RedisSetting struct {
 // 	return viper.ReadInConfig()
 // }
 
+// 环境变量前缀，环境变量可以覆盖配置文件中的任意一项，例如 XZDP_MYSQL_PASSWORD 覆盖 mysql.Password
+const envPrefix = "XZDP"
+
 func ReadConfigFile(path string) error {
 	//viper是可以开箱即用的，这样写法就类似单例模式
 	//也可以创建viper 比如 vp:=viper.New()
 	viper.SetConfigFile(path) // 指定配置文件名和位置
-	if err := viper.ReadInConfig(); err != nil {
-		return err
-	}
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	return viper.ReadInConfig()
+}
 
-	viper.WatchConfig() //该函数内部是开启了一个新协程去监听配置文件是否更新
-	//设置回调函数
-	viper.OnConfigChange(func(in fsnotify.Event) {
-		// reloadAllSection() //更新所有数据
-		//只更新日志的数据
-		err := ReadSection("log", &LogOption)
-		if err != nil {
-			slog.Error("read log section error", "err", err)
+// SetOverrides 应用命令行中的 --set key=value，优先级高于环境变量和配置文件，热加载后仍然有效
+func SetOverrides(overrides []string) error {
+	for _, o := range overrides {
+		key, value, ok := strings.Cut(o, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid override %q, want key=value", o)
 		}
-		//查看是否有更新了日志等级
-		if logger.GetLogLevel(LogOption.Level) != logger.LogLevel.Level() {
-			logger.SetLevel(LogOption.Level)
-		}
-	})
+		viper.Set(key, value)
+	}
 	return nil
 }
 
-// 分段读取
+// 分段读取，包含环境变量和 --set 的覆盖
+// viper.UnmarshalKey 只会读取配置文件中的值，所以先把合并后的配置复制一份再读取
 func ReadSection(key string, v any) error {
-	return viper.UnmarshalKey(key, v)
-}
-
-// 用于重新读取配置
-func reloadAllSection() {
-	err := ReadSection("server", &ServerOption)
-	if err != nil {
-		slog.Error("read server section error", "err", err)
-	}
-	err = ReadSection("mysql", &MysqlOption)
-	if err != nil {
-		slog.Error("read mysql section error", "err", err)
-	}
-	err = ReadSection("log", &LogOption)
-	if err != nil {
-		slog.Error("read log section error", "err", err)
+	merged := viper.New()
+	if err := merged.MergeConfigMap(viper.AllSettings()); err != nil {
+		return err
 	}
+	return merged.UnmarshalKey(key, v)
 }
 
-func InitConfig(path string) {
+func InitConfig(path string, overrides []string) {
 	if err := ReadConfigFile(path); err != nil {
 		panic(err)
 	}
 
-	err := ReadSection("server", &ServerOption)
-	if err != nil {
-		panic(err)
-	}
-
-	err = ReadSection("mysql", &MysqlOption)
-	if err != nil {
+	if err := SetOverrides(overrides); err != nil {
 		panic(err)
 	}
 
-	err = ReadSection("redis", &RedisOption)
+	cfg, err := load()
 	if err != nil {
 		panic(err)
 	}
 
-	err = ReadSection("log", &LogOption)
-	if err != nil {
-		panic(err)
-	}
-
-	err = ReadSection("jwt", &JwtOption)
-	if err != nil {
+	//启动时配置有误直接退出
+	if err = cfg.Validate(); err != nil {
 		panic(err)
 	}
+	cfg.setStartupOptions()
+	cfg.apply()
 
+	viper.WatchConfig() //该函数内部是开启了一个新协程去监听配置文件是否更新
+	//设置回调函数
+	viper.OnConfigChange(func(in fsnotify.Event) {
+		reload()
+	})
 }
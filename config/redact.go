@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redactedValue = "******"
+
+// 字段名包含这些词的字符串视为密钥，查看配置时隐藏
+var secretFieldWords = []string{"password", "secret", "apikey"}
+
+// Redacted 返回用于查看的配置，密码、密钥等字段替换为******，时长显示为 1m30s 这样的格式
+func (c *Config) Redacted() map[string]any {
+	return redact(reflect.ValueOf(c).Elem(), "").(map[string]any)
+}
+
+func redact(v reflect.Value, name string) any {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem(), name)
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.IsExported() {
+				m[f.Name] = redact(v.Field(i), f.Name)
+			}
+		}
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return []any{}
+		}
+		s := make([]any, v.Len())
+		for i := range s {
+			s[i] = redact(v.Index(i), name)
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = redact(iter.Value(), name)
+		}
+		return m
+	case reflect.String:
+		if v.String() != "" && isSecretField(name) {
+			return redactedValue
+		}
+		return v.String()
+	}
+	return v.Interface()
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, w := range secretFieldWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"xzdp/pkg/logger"
+
+	"github.com/spf13/viper"
+)
+
+// 所有配置，字段名与配置文件中的一级key对应（不区分大小写）
+// 热加载时先读到一个新的Config中，校验通过后整体替换，校验失败时继续使用原来的配置
+// 发布后的Config不能再修改，需要修改时复制一份再替换
+type Config struct {
+	Server    *ServerSetting
+	Mysql     *MysqlSetting
+	Redis     *RedisSetting
+	Log       *logger.LogSetting
+	JWT       *JWTSetting
+	Tracing   *TracingSetting
+	HotBlog   *HotBlogSetting
+	Sign      *SignSetting
+	Credit    *CreditSetting
+	Cache     *CacheSetting
+	Password  *PasswordSetting
+	SMS       *SMSSetting
+	AntiAbuse *AntiAbuseSetting
+	RBAC      *RBACSetting
+	Account   *AccountSetting
+	OAuth     *OAuthSetting
+}
+
+var (
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []func(old, new *Config)
+)
+
+// OnChange 注册配置热加载后的回调，用于应用不是每次请求都读取的配置（比如日志等级、连接池大小）
+// 回调在监听配置文件的协程中依次执行
+func OnChange(fn func(old, new *Config)) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Current 当前生效的配置，热加载和请求并发时读到的是完整的旧配置或者新配置
+// 同一个请求中需要读取多项配置时只调用一次，避免前后读到不同版本
+func Current() *Config {
+	return current.Load()
+}
+
+// 读取所有配置，包含环境变量和 --set 的覆盖；配置文件中没有的段落使用零值，由Validate检查
+func load() (*Config, error) {
+	cfg := &Config{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+	}
+	return cfg, nil
+}
+
+// 发布新配置，之后的请求通过 Current() 读到的就是新配置
+func (c *Config) apply() {
+	current.Store(c)
+}
+
+// 设置只在启动时读取的XxxOption，只在InitConfig中调用，热加载时keepRestartOnly保证这些段落不变
+func (c *Config) setStartupOptions() {
+	ServerOption = c.Server
+	MysqlOption = c.Mysql
+	RedisOption = c.Redis
+	LogOption = c.Log
+	JwtOption = c.JWT
+	TraceOption = c.Tracing
+	SMSOption = c.SMS
+	RBACOption = c.RBAC
+	OAuthOption = c.OAuth
+}
+
+// 用于重新读取配置
+func reload() {
+	next, err := load()
+	if err != nil {
+		slog.Error("读取配置文件失败，继续使用原来的配置", "err", err)
+		return
+	}
+	if err = next.Validate(); err != nil {
+		slog.Error("配置校验失败，继续使用原来的配置", "err", err)
+		return
+	}
+	old := Current()
+	keepRestartOnly(old, next)
+	next.apply()
+
+	mu.Lock()
+	fns := append([]func(old, new *Config){}, subscribers...)
+	mu.Unlock()
+	for _, fn := range fns {
+		fn(old, next)
+	}
+	slog.Info("配置已重新加载")
+}
+
+// 只在启动时读取的配置（监听端口、数据库和Redis地址、密钥等），热加载时保留原来的值，修改后需要重启
+// MySQL只有连接池大小、日志只有日志等级可以热更新
+func keepRestartOnly(old, next *Config) {
+	keep := func(section string, o, n any) {
+		if !reflect.DeepEqual(o, n) {
+			slog.Warn("配置修改需要重启才能生效", "section", section)
+		}
+	}
+	keep("server", old.Server, next.Server)
+	next.Server = old.Server
+	keep("redis", old.Redis, next.Redis)
+	next.Redis = old.Redis
+	keep("jwt", old.JWT, next.JWT)
+	next.JWT = old.JWT
+	keep("tracing", old.Tracing, next.Tracing)
+	next.Tracing = old.Tracing
+	keep("sms", old.SMS, next.SMS)
+	next.SMS = old.SMS
+	keep("oauth", old.OAuth, next.OAuth)
+	next.OAuth = old.OAuth
+	keep("rbac", old.RBAC, next.RBAC)
+	next.RBAC = old.RBAC
+
+	mysql := *old.Mysql
+	mysql.MaxIdleConns, mysql.MaxOpenConns = next.Mysql.MaxIdleConns, next.Mysql.MaxOpenConns
+	keep("mysql", &mysql, next.Mysql)
+	next.Mysql = &mysql
+
+	log := *old.Log
+	log.Level = next.Log.Level
+	keep("log", &log, next.Log)
+	next.Log = &log
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	releaseMode = "release" //与gin.ReleaseMode相同
+	// configs/config.yaml中的示例密钥
+	sampleJWTSecret = "change-me-in-production"
+)
+
+// Validate 检查配置是否完整、取值是否合理，返回所有发现的问题
+// 启动时校验失败直接退出，热加载时校验失败继续使用原来的配置
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	nonNegative := func(name string, d time.Duration) {
+		check(d >= 0, "%s must not be negative", name)
+	}
+
+	//1.服务和存储
+	check(c.Server.HttpPort != "", "server.HttpPort is required")
+	nonNegative("server.ShutdownTimeout", c.Server.ShutdownTimeout)
+	nonNegative("server.ShutdownDelay", c.Server.ShutdownDelay)
+	check(c.Mysql.Host != "", "mysql.Host is required")
+	check(c.Mysql.DbName != "", "mysql.DbName is required")
+	check(c.Mysql.MaxIdleConns >= 0, "mysql.MaxIdleConns must not be negative")
+	check(c.Mysql.MaxOpenConns >= 0, "mysql.MaxOpenConns must not be negative")
+	check(c.Mysql.MaxOpenConns == 0 || c.Mysql.MaxIdleConns <= c.Mysql.MaxOpenConns,
+		"mysql.MaxIdleConns must not exceed MaxOpenConns")
+	switch strings.ToLower(c.Redis.Mode) {
+	case "", "standalone":
+		check(c.Redis.Host != "", "redis.Host is required")
+	case "sentinel":
+		check(len(c.Redis.Addrs) > 0, "redis.Addrs is required in sentinel mode")
+		check(c.Redis.MasterName != "", "redis.MasterName is required in sentinel mode")
+	case "cluster":
+		check(len(c.Redis.Addrs) > 0, "redis.Addrs is required in cluster mode")
+		check(c.Redis.DB == 0, "redis.DB must be 0 in cluster mode")
+	default:
+		check(false, "redis.Mode %q is not one of standalone, sentinel, cluster", c.Redis.Mode)
+	}
+	check(c.Redis.DB >= 0, "redis.DB must not be negative")
+	check(c.Redis.MaxRetries >= -1, "redis.MaxRetries must not be less than -1")
+	check(!c.Redis.TLS.Enable || (c.Redis.TLS.CertFile == "") == (c.Redis.TLS.KeyFile == ""),
+		"redis.TLS.CertFile and KeyFile must be set together")
+	check(c.Redis.PoolSize >= 0, "redis.PoolSize must not be negative")
+
+	//2.日志
+	switch strings.ToLower(c.Log.Level) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		check(false, "log.Level %q is not one of debug, info, warn, error", c.Log.Level)
+	}
+
+	//3.令牌
+	check(c.JWT.Secret != "" || len(c.JWT.Keys) > 0, "jwt.Secret or jwt.Keys is required")
+	check(c.JWT.Expire > 0, "jwt.Expire must be positive")
+	check(c.JWT.RefreshExpire >= c.JWT.Expire, "jwt.RefreshExpire must not be shorter than Expire")
+	check(c.JWT.MaxSessions >= 0, "jwt.MaxSessions must not be negative")
+	for i, k := range c.JWT.Keys {
+		check(k.Kid != "", "jwt.Keys[%d].Kid is required", i)
+		// 配置文件中的示例密钥是公开的，正式环境必须替换
+		check(c.Server.RunMode != releaseMode || k.Secret != sampleJWTSecret,
+			"jwt.Keys[%d].Secret is the sample key, replace it in release mode", i)
+	}
+	check(c.Server.RunMode != releaseMode || c.JWT.Secret != sampleJWTSecret, "jwt.Secret is the sample key, replace it in release mode")
+
+	//4.追踪
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.SampleRatio must be between 0 and 1")
+
+	//5.业务配置
+	check(c.HotBlog.DecayHours > 0, "hotBlog.DecayHours must be positive")
+	check(c.HotBlog.PageSize > 0, "hotBlog.PageSize must be positive")
+	// RankSize为0时每次互动都会清空排行榜，热加载也会经过这里的校验
+	check(c.HotBlog.RankSize > 0, "hotBlog.RankSize must be positive")
+	check(c.HotBlog.LikeWeight >= 0 && c.HotBlog.CommentWeight >= 0, "hotBlog.LikeWeight and CommentWeight must not be negative")
+	check(c.Sign.BackupQuota >= 0, "sign.BackupQuota must not be negative")
+	for i := 1; i < len(c.Credit.Levels); i++ {
+		check(c.Credit.Levels[i] > c.Credit.Levels[i-1], "credit.Levels must be increasing")
+	}
+	nonNegative("cache.Shop", c.Cache.Shop)
+	nonNegative("cache.ShopType", c.Cache.ShopType)
+	nonNegative("cache.Voucher", c.Cache.Voucher)
+	nonNegative("cache.UserInfo", c.Cache.UserInfo)
+	nonNegative("cache.Blog", c.Cache.Blog)
+	nonNegative("cache.SeckillStock", c.Cache.SeckillStock)
+	check(c.Password.Memory > 0 && c.Password.Iterations > 0 && c.Password.Parallelism > 0,
+		"password.Memory, Iterations and Parallelism must be positive")
+	check(c.Password.MaxFailures >= 0, "password.MaxFailures must not be negative")
+
+	//6.防刷
+	for name, windows := range map[string][]RateLimitWindow{
+		"antiAbuse.PhoneWindows":      c.AntiAbuse.PhoneWindows,
+		"antiAbuse.IPWindows":         c.AntiAbuse.IPWindows,
+		"antiAbuse.LoginIPWindows":    c.AntiAbuse.LoginIPWindows,
+		"antiAbuse.LoginPhoneWindows": c.AntiAbuse.LoginPhoneWindows,
+	} {
+		for i, w := range windows {
+			check(w.Duration > 0 && w.Limit > 0, "%s[%d] Duration and Limit must be positive", name, i)
+		}
+	}
+	check(c.AntiAbuse.MaxCodeAttempts >= 0, "antiAbuse.MaxCodeAttempts must not be negative")
+	// 封禁记录没有过期时间时永远不会解封，检查封禁时也会忽略它
+	check(c.AntiAbuse.BanThreshold <= 0 || c.AntiAbuse.BanWindow > 0 && c.AntiAbuse.BanDuration > 0,
+		"antiAbuse.BanWindow and BanDuration must be positive when BanThreshold is set")
+
+	//7.第三方登录
+	seen := map[string]bool{}
+	for i, p := range c.OAuth.Providers {
+		check(p.Name != "", "oauth.Providers[%d].Name is required", i)
+		check(!seen[p.Name], "oauth.Providers[%d].Name %q is duplicated", i, p.Name)
+		seen[p.Name] = true
+		check(p.ClientID != "" && p.AuthURL != "" && p.TokenURL != "" && p.JWKSURL != "" && p.Issuer != "" && p.RedirectURL != "",
+			"oauth.Providers[%d] ClientID, AuthURL, TokenURL, JWKSURL, Issuer and RedirectURL are required", i)
+	}
+	return errors.Join(errs...)
+}
@@ -16,84 +16,119 @@ import (
 )
 
 var (
-	Q                = new(Query)
-	TbBlog           *tbBlog
-	TbBlogComment    *tbBlogComment
-	TbFollow         *tbFollow
-	TbSeckillVoucher *tbSeckillVoucher
-	TbShop           *tbShop
-	TbShopType       *tbShopType
-	TbSign           *tbSign
-	TbUser           *tbUser
-	TbUserInfo       *tbUserInfo
-	TbVoucher        *tbVoucher
-	TbVoucherOrder   *tbVoucherOrder
+	Q                 = new(Query)
+	TbAccountDeletion *tbAccountDeletion
+	TbBlog            *tbBlog
+	TbBlogComment     *tbBlogComment
+	TbCreditLog       *tbCreditLog
+	TbFollow          *tbFollow
+	TbPermission      *tbPermission
+	TbRole            *tbRole
+	TbRolePermission  *tbRolePermission
+	TbSeckillVoucher  *tbSeckillVoucher
+	TbShop            *tbShop
+	TbShopType        *tbShopType
+	TbSign            *tbSign
+	TbUser            *tbUser
+	TbUserIdentity    *tbUserIdentity
+	TbUserInfo        *tbUserInfo
+	TbUserRole        *tbUserRole
+	TbVoucher         *tbVoucher
+	TbVoucherOrder    *tbVoucherOrder
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
+	TbAccountDeletion = &Q.TbAccountDeletion
 	TbBlog = &Q.TbBlog
 	TbBlogComment = &Q.TbBlogComment
+	TbCreditLog = &Q.TbCreditLog
 	TbFollow = &Q.TbFollow
+	TbPermission = &Q.TbPermission
+	TbRole = &Q.TbRole
+	TbRolePermission = &Q.TbRolePermission
 	TbSeckillVoucher = &Q.TbSeckillVoucher
 	TbShop = &Q.TbShop
 	TbShopType = &Q.TbShopType
 	TbSign = &Q.TbSign
 	TbUser = &Q.TbUser
+	TbUserIdentity = &Q.TbUserIdentity
 	TbUserInfo = &Q.TbUserInfo
+	TbUserRole = &Q.TbUserRole
 	TbVoucher = &Q.TbVoucher
 	TbVoucherOrder = &Q.TbVoucherOrder
 }
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:               db,
-		TbBlog:           newTbBlog(db, opts...),
-		TbBlogComment:    newTbBlogComment(db, opts...),
-		TbFollow:         newTbFollow(db, opts...),
-		TbSeckillVoucher: newTbSeckillVoucher(db, opts...),
-		TbShop:           newTbShop(db, opts...),
-		TbShopType:       newTbShopType(db, opts...),
-		TbSign:           newTbSign(db, opts...),
-		TbUser:           newTbUser(db, opts...),
-		TbUserInfo:       newTbUserInfo(db, opts...),
-		TbVoucher:        newTbVoucher(db, opts...),
-		TbVoucherOrder:   newTbVoucherOrder(db, opts...),
+		db:                db,
+		TbAccountDeletion: newTbAccountDeletion(db, opts...),
+		TbBlog:            newTbBlog(db, opts...),
+		TbBlogComment:     newTbBlogComment(db, opts...),
+		TbCreditLog:       newTbCreditLog(db, opts...),
+		TbFollow:          newTbFollow(db, opts...),
+		TbPermission:      newTbPermission(db, opts...),
+		TbRole:            newTbRole(db, opts...),
+		TbRolePermission:  newTbRolePermission(db, opts...),
+		TbSeckillVoucher:  newTbSeckillVoucher(db, opts...),
+		TbShop:            newTbShop(db, opts...),
+		TbShopType:        newTbShopType(db, opts...),
+		TbSign:            newTbSign(db, opts...),
+		TbUser:            newTbUser(db, opts...),
+		TbUserIdentity:    newTbUserIdentity(db, opts...),
+		TbUserInfo:        newTbUserInfo(db, opts...),
+		TbUserRole:        newTbUserRole(db, opts...),
+		TbVoucher:         newTbVoucher(db, opts...),
+		TbVoucherOrder:    newTbVoucherOrder(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	TbBlog           tbBlog
-	TbBlogComment    tbBlogComment
-	TbFollow         tbFollow
-	TbSeckillVoucher tbSeckillVoucher
-	TbShop           tbShop
-	TbShopType       tbShopType
-	TbSign           tbSign
-	TbUser           tbUser
-	TbUserInfo       tbUserInfo
-	TbVoucher        tbVoucher
-	TbVoucherOrder   tbVoucherOrder
+	TbAccountDeletion tbAccountDeletion
+	TbBlog            tbBlog
+	TbBlogComment     tbBlogComment
+	TbCreditLog       tbCreditLog
+	TbFollow          tbFollow
+	TbPermission      tbPermission
+	TbRole            tbRole
+	TbRolePermission  tbRolePermission
+	TbSeckillVoucher  tbSeckillVoucher
+	TbShop            tbShop
+	TbShopType        tbShopType
+	TbSign            tbSign
+	TbUser            tbUser
+	TbUserIdentity    tbUserIdentity
+	TbUserInfo        tbUserInfo
+	TbUserRole        tbUserRole
+	TbVoucher         tbVoucher
+	TbVoucherOrder    tbVoucherOrder
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:               db,
-		TbBlog:           q.TbBlog.clone(db),
-		TbBlogComment:    q.TbBlogComment.clone(db),
-		TbFollow:         q.TbFollow.clone(db),
-		TbSeckillVoucher: q.TbSeckillVoucher.clone(db),
-		TbShop:           q.TbShop.clone(db),
-		TbShopType:       q.TbShopType.clone(db),
-		TbSign:           q.TbSign.clone(db),
-		TbUser:           q.TbUser.clone(db),
-		TbUserInfo:       q.TbUserInfo.clone(db),
-		TbVoucher:        q.TbVoucher.clone(db),
-		TbVoucherOrder:   q.TbVoucherOrder.clone(db),
+		db:                db,
+		TbAccountDeletion: q.TbAccountDeletion.clone(db),
+		TbBlog:            q.TbBlog.clone(db),
+		TbBlogComment:     q.TbBlogComment.clone(db),
+		TbCreditLog:       q.TbCreditLog.clone(db),
+		TbFollow:          q.TbFollow.clone(db),
+		TbPermission:      q.TbPermission.clone(db),
+		TbRole:            q.TbRole.clone(db),
+		TbRolePermission:  q.TbRolePermission.clone(db),
+		TbSeckillVoucher:  q.TbSeckillVoucher.clone(db),
+		TbShop:            q.TbShop.clone(db),
+		TbShopType:        q.TbShopType.clone(db),
+		TbSign:            q.TbSign.clone(db),
+		TbUser:            q.TbUser.clone(db),
+		TbUserIdentity:    q.TbUserIdentity.clone(db),
+		TbUserInfo:        q.TbUserInfo.clone(db),
+		TbUserRole:        q.TbUserRole.clone(db),
+		TbVoucher:         q.TbVoucher.clone(db),
+		TbVoucherOrder:    q.TbVoucherOrder.clone(db),
 	}
 }
 
@@ -107,48 +142,69 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:               db,
-		TbBlog:           q.TbBlog.replaceDB(db),
-		TbBlogComment:    q.TbBlogComment.replaceDB(db),
-		TbFollow:         q.TbFollow.replaceDB(db),
-		TbSeckillVoucher: q.TbSeckillVoucher.replaceDB(db),
-		TbShop:           q.TbShop.replaceDB(db),
-		TbShopType:       q.TbShopType.replaceDB(db),
-		TbSign:           q.TbSign.replaceDB(db),
-		TbUser:           q.TbUser.replaceDB(db),
-		TbUserInfo:       q.TbUserInfo.replaceDB(db),
-		TbVoucher:        q.TbVoucher.replaceDB(db),
-		TbVoucherOrder:   q.TbVoucherOrder.replaceDB(db),
+		db:                db,
+		TbAccountDeletion: q.TbAccountDeletion.replaceDB(db),
+		TbBlog:            q.TbBlog.replaceDB(db),
+		TbBlogComment:     q.TbBlogComment.replaceDB(db),
+		TbCreditLog:       q.TbCreditLog.replaceDB(db),
+		TbFollow:          q.TbFollow.replaceDB(db),
+		TbPermission:      q.TbPermission.replaceDB(db),
+		TbRole:            q.TbRole.replaceDB(db),
+		TbRolePermission:  q.TbRolePermission.replaceDB(db),
+		TbSeckillVoucher:  q.TbSeckillVoucher.replaceDB(db),
+		TbShop:            q.TbShop.replaceDB(db),
+		TbShopType:        q.TbShopType.replaceDB(db),
+		TbSign:            q.TbSign.replaceDB(db),
+		TbUser:            q.TbUser.replaceDB(db),
+		TbUserIdentity:    q.TbUserIdentity.replaceDB(db),
+		TbUserInfo:        q.TbUserInfo.replaceDB(db),
+		TbUserRole:        q.TbUserRole.replaceDB(db),
+		TbVoucher:         q.TbVoucher.replaceDB(db),
+		TbVoucherOrder:    q.TbVoucherOrder.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	TbBlog           ITbBlogDo
-	TbBlogComment    ITbBlogCommentDo
-	TbFollow         ITbFollowDo
-	TbSeckillVoucher ITbSeckillVoucherDo
-	TbShop           ITbShopDo
-	TbShopType       ITbShopTypeDo
-	TbSign           ITbSignDo
-	TbUser           ITbUserDo
-	TbUserInfo       ITbUserInfoDo
-	TbVoucher        ITbVoucherDo
-	TbVoucherOrder   ITbVoucherOrderDo
+	TbAccountDeletion ITbAccountDeletionDo
+	TbBlog            ITbBlogDo
+	TbBlogComment     ITbBlogCommentDo
+	TbCreditLog       ITbCreditLogDo
+	TbFollow          ITbFollowDo
+	TbPermission      ITbPermissionDo
+	TbRole            ITbRoleDo
+	TbRolePermission  ITbRolePermissionDo
+	TbSeckillVoucher  ITbSeckillVoucherDo
+	TbShop            ITbShopDo
+	TbShopType        ITbShopTypeDo
+	TbSign            ITbSignDo
+	TbUser            ITbUserDo
+	TbUserIdentity    ITbUserIdentityDo
+	TbUserInfo        ITbUserInfoDo
+	TbUserRole        ITbUserRoleDo
+	TbVoucher         ITbVoucherDo
+	TbVoucherOrder    ITbVoucherOrderDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		TbBlog:           q.TbBlog.WithContext(ctx),
-		TbBlogComment:    q.TbBlogComment.WithContext(ctx),
-		TbFollow:         q.TbFollow.WithContext(ctx),
-		TbSeckillVoucher: q.TbSeckillVoucher.WithContext(ctx),
-		TbShop:           q.TbShop.WithContext(ctx),
-		TbShopType:       q.TbShopType.WithContext(ctx),
-		TbSign:           q.TbSign.WithContext(ctx),
-		TbUser:           q.TbUser.WithContext(ctx),
-		TbUserInfo:       q.TbUserInfo.WithContext(ctx),
-		TbVoucher:        q.TbVoucher.WithContext(ctx),
-		TbVoucherOrder:   q.TbVoucherOrder.WithContext(ctx),
+		TbAccountDeletion: q.TbAccountDeletion.WithContext(ctx),
+		TbBlog:            q.TbBlog.WithContext(ctx),
+		TbBlogComment:     q.TbBlogComment.WithContext(ctx),
+		TbCreditLog:       q.TbCreditLog.WithContext(ctx),
+		TbFollow:          q.TbFollow.WithContext(ctx),
+		TbPermission:      q.TbPermission.WithContext(ctx),
+		TbRole:            q.TbRole.WithContext(ctx),
+		TbRolePermission:  q.TbRolePermission.WithContext(ctx),
+		TbSeckillVoucher:  q.TbSeckillVoucher.WithContext(ctx),
+		TbShop:            q.TbShop.WithContext(ctx),
+		TbShopType:        q.TbShopType.WithContext(ctx),
+		TbSign:            q.TbSign.WithContext(ctx),
+		TbUser:            q.TbUser.WithContext(ctx),
+		TbUserIdentity:    q.TbUserIdentity.WithContext(ctx),
+		TbUserInfo:        q.TbUserInfo.WithContext(ctx),
+		TbUserRole:        q.TbUserRole.WithContext(ctx),
+		TbVoucher:         q.TbVoucher.WithContext(ctx),
+		TbVoucherOrder:    q.TbVoucherOrder.WithContext(ctx),
 	}
 }
 
@@ -0,0 +1,403 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbAccountDeletion(db *gorm.DB, opts ...gen.DOOption) tbAccountDeletion {
+	_tbAccountDeletion := tbAccountDeletion{}
+
+	_tbAccountDeletion.tbAccountDeletionDo.UseDB(db, opts...)
+	_tbAccountDeletion.tbAccountDeletionDo.UseModel(&model.TbAccountDeletion{})
+
+	tableName := _tbAccountDeletion.tbAccountDeletionDo.TableName()
+	_tbAccountDeletion.ALL = field.NewAsterisk(tableName)
+	_tbAccountDeletion.ID = field.NewUint64(tableName, "id")
+	_tbAccountDeletion.UserID = field.NewUint64(tableName, "user_id")
+	_tbAccountDeletion.Status = field.NewUint32(tableName, "status")
+	_tbAccountDeletion.PurgeTime = field.NewTime(tableName, "purge_time")
+	_tbAccountDeletion.CreateTime = field.NewTime(tableName, "create_time")
+	_tbAccountDeletion.UpdateTime = field.NewTime(tableName, "update_time")
+
+	_tbAccountDeletion.fillFieldMap()
+
+	return _tbAccountDeletion
+}
+
+type tbAccountDeletion struct {
+	tbAccountDeletionDo
+
+	ALL        field.Asterisk
+	ID         field.Uint64 // 主键
+	UserID     field.Uint64 // 用户id
+	Status     field.Uint32 // 状态：0等待注销，1已注销
+	PurgeTime  field.Time   // 冷静期结束、执行注销的时间
+	CreateTime field.Time   // 申请时间
+	UpdateTime field.Time   // 更新时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbAccountDeletion) Table(newTableName string) *tbAccountDeletion {
+	t.tbAccountDeletionDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbAccountDeletion) As(alias string) *tbAccountDeletion {
+	t.tbAccountDeletionDo.DO = *(t.tbAccountDeletionDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbAccountDeletion) updateTableName(table string) *tbAccountDeletion {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.UserID = field.NewUint64(table, "user_id")
+	t.Status = field.NewUint32(table, "status")
+	t.PurgeTime = field.NewTime(table, "purge_time")
+	t.CreateTime = field.NewTime(table, "create_time")
+	t.UpdateTime = field.NewTime(table, "update_time")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbAccountDeletion) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbAccountDeletion) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 6)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["user_id"] = t.UserID
+	t.fieldMap["status"] = t.Status
+	t.fieldMap["purge_time"] = t.PurgeTime
+	t.fieldMap["create_time"] = t.CreateTime
+	t.fieldMap["update_time"] = t.UpdateTime
+}
+
+func (t tbAccountDeletion) clone(db *gorm.DB) tbAccountDeletion {
+	t.tbAccountDeletionDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbAccountDeletion) replaceDB(db *gorm.DB) tbAccountDeletion {
+	t.tbAccountDeletionDo.ReplaceDB(db)
+	return t
+}
+
+type tbAccountDeletionDo struct{ gen.DO }
+
+type ITbAccountDeletionDo interface {
+	gen.SubQuery
+	Debug() ITbAccountDeletionDo
+	WithContext(ctx context.Context) ITbAccountDeletionDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbAccountDeletionDo
+	WriteDB() ITbAccountDeletionDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbAccountDeletionDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbAccountDeletionDo
+	Not(conds ...gen.Condition) ITbAccountDeletionDo
+	Or(conds ...gen.Condition) ITbAccountDeletionDo
+	Select(conds ...field.Expr) ITbAccountDeletionDo
+	Where(conds ...gen.Condition) ITbAccountDeletionDo
+	Order(conds ...field.Expr) ITbAccountDeletionDo
+	Distinct(cols ...field.Expr) ITbAccountDeletionDo
+	Omit(cols ...field.Expr) ITbAccountDeletionDo
+	Join(table schema.Tabler, on ...field.Expr) ITbAccountDeletionDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbAccountDeletionDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbAccountDeletionDo
+	Group(cols ...field.Expr) ITbAccountDeletionDo
+	Having(conds ...gen.Condition) ITbAccountDeletionDo
+	Limit(limit int) ITbAccountDeletionDo
+	Offset(offset int) ITbAccountDeletionDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbAccountDeletionDo
+	Unscoped() ITbAccountDeletionDo
+	Create(values ...*model.TbAccountDeletion) error
+	CreateInBatches(values []*model.TbAccountDeletion, batchSize int) error
+	Save(values ...*model.TbAccountDeletion) error
+	First() (*model.TbAccountDeletion, error)
+	Take() (*model.TbAccountDeletion, error)
+	Last() (*model.TbAccountDeletion, error)
+	Find() ([]*model.TbAccountDeletion, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbAccountDeletion, err error)
+	FindInBatches(result *[]*model.TbAccountDeletion, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbAccountDeletion) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbAccountDeletionDo
+	Assign(attrs ...field.AssignExpr) ITbAccountDeletionDo
+	Joins(fields ...field.RelationField) ITbAccountDeletionDo
+	Preload(fields ...field.RelationField) ITbAccountDeletionDo
+	FirstOrInit() (*model.TbAccountDeletion, error)
+	FirstOrCreate() (*model.TbAccountDeletion, error)
+	FindByPage(offset int, limit int) (result []*model.TbAccountDeletion, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbAccountDeletionDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbAccountDeletionDo) Debug() ITbAccountDeletionDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbAccountDeletionDo) WithContext(ctx context.Context) ITbAccountDeletionDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbAccountDeletionDo) ReadDB() ITbAccountDeletionDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbAccountDeletionDo) WriteDB() ITbAccountDeletionDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbAccountDeletionDo) Session(config *gorm.Session) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbAccountDeletionDo) Clauses(conds ...clause.Expression) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbAccountDeletionDo) Returning(value interface{}, columns ...string) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbAccountDeletionDo) Not(conds ...gen.Condition) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbAccountDeletionDo) Or(conds ...gen.Condition) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbAccountDeletionDo) Select(conds ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbAccountDeletionDo) Where(conds ...gen.Condition) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbAccountDeletionDo) Order(conds ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbAccountDeletionDo) Distinct(cols ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbAccountDeletionDo) Omit(cols ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbAccountDeletionDo) Join(table schema.Tabler, on ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbAccountDeletionDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbAccountDeletionDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbAccountDeletionDo) Group(cols ...field.Expr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbAccountDeletionDo) Having(conds ...gen.Condition) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbAccountDeletionDo) Limit(limit int) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbAccountDeletionDo) Offset(offset int) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbAccountDeletionDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbAccountDeletionDo) Unscoped() ITbAccountDeletionDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbAccountDeletionDo) Create(values ...*model.TbAccountDeletion) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbAccountDeletionDo) CreateInBatches(values []*model.TbAccountDeletion, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbAccountDeletionDo) Save(values ...*model.TbAccountDeletion) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbAccountDeletionDo) First() (*model.TbAccountDeletion, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbAccountDeletion), nil
+	}
+}
+
+func (t tbAccountDeletionDo) Take() (*model.TbAccountDeletion, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbAccountDeletion), nil
+	}
+}
+
+func (t tbAccountDeletionDo) Last() (*model.TbAccountDeletion, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbAccountDeletion), nil
+	}
+}
+
+func (t tbAccountDeletionDo) Find() ([]*model.TbAccountDeletion, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbAccountDeletion), err
+}
+
+func (t tbAccountDeletionDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbAccountDeletion, err error) {
+	buf := make([]*model.TbAccountDeletion, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbAccountDeletionDo) FindInBatches(result *[]*model.TbAccountDeletion, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbAccountDeletionDo) Attrs(attrs ...field.AssignExpr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbAccountDeletionDo) Assign(attrs ...field.AssignExpr) ITbAccountDeletionDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbAccountDeletionDo) Joins(fields ...field.RelationField) ITbAccountDeletionDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbAccountDeletionDo) Preload(fields ...field.RelationField) ITbAccountDeletionDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbAccountDeletionDo) FirstOrInit() (*model.TbAccountDeletion, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbAccountDeletion), nil
+	}
+}
+
+func (t tbAccountDeletionDo) FirstOrCreate() (*model.TbAccountDeletion, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbAccountDeletion), nil
+	}
+}
+
+func (t tbAccountDeletionDo) FindByPage(offset int, limit int) (result []*model.TbAccountDeletion, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbAccountDeletionDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbAccountDeletionDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbAccountDeletionDo) Delete(models ...*model.TbAccountDeletion) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbAccountDeletionDo) withDO(do gen.Dao) *tbAccountDeletionDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
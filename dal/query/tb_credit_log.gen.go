@@ -0,0 +1,407 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbCreditLog(db *gorm.DB, opts ...gen.DOOption) tbCreditLog {
+	_tbCreditLog := tbCreditLog{}
+
+	_tbCreditLog.tbCreditLogDo.UseDB(db, opts...)
+	_tbCreditLog.tbCreditLogDo.UseModel(&model.TbCreditLog{})
+
+	tableName := _tbCreditLog.tbCreditLogDo.TableName()
+	_tbCreditLog.ALL = field.NewAsterisk(tableName)
+	_tbCreditLog.ID = field.NewUint64(tableName, "id")
+	_tbCreditLog.UserID = field.NewUint64(tableName, "user_id")
+	_tbCreditLog.Amount = field.NewInt32(tableName, "amount")
+	_tbCreditLog.Balance = field.NewUint32(tableName, "balance")
+	_tbCreditLog.Reason = field.NewString(tableName, "reason")
+	_tbCreditLog.RefID = field.NewUint64(tableName, "ref_id")
+	_tbCreditLog.CreateTime = field.NewTime(tableName, "create_time")
+
+	_tbCreditLog.fillFieldMap()
+
+	return _tbCreditLog
+}
+
+type tbCreditLog struct {
+	tbCreditLogDo
+
+	ALL        field.Asterisk
+	ID         field.Uint64 // 主键
+	UserID     field.Uint64 // 用户id
+	Amount     field.Int32  // 积分变动，正数为获得，负数为扣除
+	Balance    field.Uint32 // 变动后的积分
+	Reason     field.String // 积分来源：sign,streak,blog,liked
+	RefID      field.Uint64 // 关联的业务id，例如博客id
+	CreateTime field.Time   // 创建时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbCreditLog) Table(newTableName string) *tbCreditLog {
+	t.tbCreditLogDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbCreditLog) As(alias string) *tbCreditLog {
+	t.tbCreditLogDo.DO = *(t.tbCreditLogDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbCreditLog) updateTableName(table string) *tbCreditLog {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.UserID = field.NewUint64(table, "user_id")
+	t.Amount = field.NewInt32(table, "amount")
+	t.Balance = field.NewUint32(table, "balance")
+	t.Reason = field.NewString(table, "reason")
+	t.RefID = field.NewUint64(table, "ref_id")
+	t.CreateTime = field.NewTime(table, "create_time")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbCreditLog) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbCreditLog) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 7)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["user_id"] = t.UserID
+	t.fieldMap["amount"] = t.Amount
+	t.fieldMap["balance"] = t.Balance
+	t.fieldMap["reason"] = t.Reason
+	t.fieldMap["ref_id"] = t.RefID
+	t.fieldMap["create_time"] = t.CreateTime
+}
+
+func (t tbCreditLog) clone(db *gorm.DB) tbCreditLog {
+	t.tbCreditLogDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbCreditLog) replaceDB(db *gorm.DB) tbCreditLog {
+	t.tbCreditLogDo.ReplaceDB(db)
+	return t
+}
+
+type tbCreditLogDo struct{ gen.DO }
+
+type ITbCreditLogDo interface {
+	gen.SubQuery
+	Debug() ITbCreditLogDo
+	WithContext(ctx context.Context) ITbCreditLogDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbCreditLogDo
+	WriteDB() ITbCreditLogDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbCreditLogDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbCreditLogDo
+	Not(conds ...gen.Condition) ITbCreditLogDo
+	Or(conds ...gen.Condition) ITbCreditLogDo
+	Select(conds ...field.Expr) ITbCreditLogDo
+	Where(conds ...gen.Condition) ITbCreditLogDo
+	Order(conds ...field.Expr) ITbCreditLogDo
+	Distinct(cols ...field.Expr) ITbCreditLogDo
+	Omit(cols ...field.Expr) ITbCreditLogDo
+	Join(table schema.Tabler, on ...field.Expr) ITbCreditLogDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbCreditLogDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbCreditLogDo
+	Group(cols ...field.Expr) ITbCreditLogDo
+	Having(conds ...gen.Condition) ITbCreditLogDo
+	Limit(limit int) ITbCreditLogDo
+	Offset(offset int) ITbCreditLogDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbCreditLogDo
+	Unscoped() ITbCreditLogDo
+	Create(values ...*model.TbCreditLog) error
+	CreateInBatches(values []*model.TbCreditLog, batchSize int) error
+	Save(values ...*model.TbCreditLog) error
+	First() (*model.TbCreditLog, error)
+	Take() (*model.TbCreditLog, error)
+	Last() (*model.TbCreditLog, error)
+	Find() ([]*model.TbCreditLog, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbCreditLog, err error)
+	FindInBatches(result *[]*model.TbCreditLog, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbCreditLog) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbCreditLogDo
+	Assign(attrs ...field.AssignExpr) ITbCreditLogDo
+	Joins(fields ...field.RelationField) ITbCreditLogDo
+	Preload(fields ...field.RelationField) ITbCreditLogDo
+	FirstOrInit() (*model.TbCreditLog, error)
+	FirstOrCreate() (*model.TbCreditLog, error)
+	FindByPage(offset int, limit int) (result []*model.TbCreditLog, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbCreditLogDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbCreditLogDo) Debug() ITbCreditLogDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbCreditLogDo) WithContext(ctx context.Context) ITbCreditLogDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbCreditLogDo) ReadDB() ITbCreditLogDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbCreditLogDo) WriteDB() ITbCreditLogDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbCreditLogDo) Session(config *gorm.Session) ITbCreditLogDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbCreditLogDo) Clauses(conds ...clause.Expression) ITbCreditLogDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbCreditLogDo) Returning(value interface{}, columns ...string) ITbCreditLogDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbCreditLogDo) Not(conds ...gen.Condition) ITbCreditLogDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbCreditLogDo) Or(conds ...gen.Condition) ITbCreditLogDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbCreditLogDo) Select(conds ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbCreditLogDo) Where(conds ...gen.Condition) ITbCreditLogDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbCreditLogDo) Order(conds ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbCreditLogDo) Distinct(cols ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbCreditLogDo) Omit(cols ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbCreditLogDo) Join(table schema.Tabler, on ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbCreditLogDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbCreditLogDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbCreditLogDo) Group(cols ...field.Expr) ITbCreditLogDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbCreditLogDo) Having(conds ...gen.Condition) ITbCreditLogDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbCreditLogDo) Limit(limit int) ITbCreditLogDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbCreditLogDo) Offset(offset int) ITbCreditLogDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbCreditLogDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbCreditLogDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbCreditLogDo) Unscoped() ITbCreditLogDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbCreditLogDo) Create(values ...*model.TbCreditLog) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbCreditLogDo) CreateInBatches(values []*model.TbCreditLog, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbCreditLogDo) Save(values ...*model.TbCreditLog) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbCreditLogDo) First() (*model.TbCreditLog, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbCreditLog), nil
+	}
+}
+
+func (t tbCreditLogDo) Take() (*model.TbCreditLog, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbCreditLog), nil
+	}
+}
+
+func (t tbCreditLogDo) Last() (*model.TbCreditLog, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbCreditLog), nil
+	}
+}
+
+func (t tbCreditLogDo) Find() ([]*model.TbCreditLog, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbCreditLog), err
+}
+
+func (t tbCreditLogDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbCreditLog, err error) {
+	buf := make([]*model.TbCreditLog, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbCreditLogDo) FindInBatches(result *[]*model.TbCreditLog, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbCreditLogDo) Attrs(attrs ...field.AssignExpr) ITbCreditLogDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbCreditLogDo) Assign(attrs ...field.AssignExpr) ITbCreditLogDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbCreditLogDo) Joins(fields ...field.RelationField) ITbCreditLogDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbCreditLogDo) Preload(fields ...field.RelationField) ITbCreditLogDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbCreditLogDo) FirstOrInit() (*model.TbCreditLog, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbCreditLog), nil
+	}
+}
+
+func (t tbCreditLogDo) FirstOrCreate() (*model.TbCreditLog, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbCreditLog), nil
+	}
+}
+
+func (t tbCreditLogDo) FindByPage(offset int, limit int) (result []*model.TbCreditLog, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbCreditLogDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbCreditLogDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbCreditLogDo) Delete(models ...*model.TbCreditLog) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbCreditLogDo) withDO(do gen.Dao) *tbCreditLogDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
@@ -0,0 +1,395 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbPermission(db *gorm.DB, opts ...gen.DOOption) tbPermission {
+	_tbPermission := tbPermission{}
+
+	_tbPermission.tbPermissionDo.UseDB(db, opts...)
+	_tbPermission.tbPermissionDo.UseModel(&model.TbPermission{})
+
+	tableName := _tbPermission.tbPermissionDo.TableName()
+	_tbPermission.ALL = field.NewAsterisk(tableName)
+	_tbPermission.ID = field.NewUint64(tableName, "id")
+	_tbPermission.Code = field.NewString(tableName, "code")
+	_tbPermission.Name = field.NewString(tableName, "name")
+	_tbPermission.CreateTime = field.NewTime(tableName, "create_time")
+
+	_tbPermission.fillFieldMap()
+
+	return _tbPermission
+}
+
+type tbPermission struct {
+	tbPermissionDo
+
+	ALL        field.Asterisk
+	ID         field.Uint64 // 主键
+	Code       field.String // 权限编码，格式为 资源:操作，例如shop:write
+	Name       field.String // 权限名称
+	CreateTime field.Time   // 创建时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbPermission) Table(newTableName string) *tbPermission {
+	t.tbPermissionDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbPermission) As(alias string) *tbPermission {
+	t.tbPermissionDo.DO = *(t.tbPermissionDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbPermission) updateTableName(table string) *tbPermission {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.Code = field.NewString(table, "code")
+	t.Name = field.NewString(table, "name")
+	t.CreateTime = field.NewTime(table, "create_time")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbPermission) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbPermission) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 4)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["code"] = t.Code
+	t.fieldMap["name"] = t.Name
+	t.fieldMap["create_time"] = t.CreateTime
+}
+
+func (t tbPermission) clone(db *gorm.DB) tbPermission {
+	t.tbPermissionDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbPermission) replaceDB(db *gorm.DB) tbPermission {
+	t.tbPermissionDo.ReplaceDB(db)
+	return t
+}
+
+type tbPermissionDo struct{ gen.DO }
+
+type ITbPermissionDo interface {
+	gen.SubQuery
+	Debug() ITbPermissionDo
+	WithContext(ctx context.Context) ITbPermissionDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbPermissionDo
+	WriteDB() ITbPermissionDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbPermissionDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbPermissionDo
+	Not(conds ...gen.Condition) ITbPermissionDo
+	Or(conds ...gen.Condition) ITbPermissionDo
+	Select(conds ...field.Expr) ITbPermissionDo
+	Where(conds ...gen.Condition) ITbPermissionDo
+	Order(conds ...field.Expr) ITbPermissionDo
+	Distinct(cols ...field.Expr) ITbPermissionDo
+	Omit(cols ...field.Expr) ITbPermissionDo
+	Join(table schema.Tabler, on ...field.Expr) ITbPermissionDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbPermissionDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbPermissionDo
+	Group(cols ...field.Expr) ITbPermissionDo
+	Having(conds ...gen.Condition) ITbPermissionDo
+	Limit(limit int) ITbPermissionDo
+	Offset(offset int) ITbPermissionDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbPermissionDo
+	Unscoped() ITbPermissionDo
+	Create(values ...*model.TbPermission) error
+	CreateInBatches(values []*model.TbPermission, batchSize int) error
+	Save(values ...*model.TbPermission) error
+	First() (*model.TbPermission, error)
+	Take() (*model.TbPermission, error)
+	Last() (*model.TbPermission, error)
+	Find() ([]*model.TbPermission, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbPermission, err error)
+	FindInBatches(result *[]*model.TbPermission, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbPermission) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbPermissionDo
+	Assign(attrs ...field.AssignExpr) ITbPermissionDo
+	Joins(fields ...field.RelationField) ITbPermissionDo
+	Preload(fields ...field.RelationField) ITbPermissionDo
+	FirstOrInit() (*model.TbPermission, error)
+	FirstOrCreate() (*model.TbPermission, error)
+	FindByPage(offset int, limit int) (result []*model.TbPermission, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbPermissionDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbPermissionDo) Debug() ITbPermissionDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbPermissionDo) WithContext(ctx context.Context) ITbPermissionDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbPermissionDo) ReadDB() ITbPermissionDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbPermissionDo) WriteDB() ITbPermissionDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbPermissionDo) Session(config *gorm.Session) ITbPermissionDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbPermissionDo) Clauses(conds ...clause.Expression) ITbPermissionDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbPermissionDo) Returning(value interface{}, columns ...string) ITbPermissionDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbPermissionDo) Not(conds ...gen.Condition) ITbPermissionDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbPermissionDo) Or(conds ...gen.Condition) ITbPermissionDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbPermissionDo) Select(conds ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbPermissionDo) Where(conds ...gen.Condition) ITbPermissionDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbPermissionDo) Order(conds ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbPermissionDo) Distinct(cols ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbPermissionDo) Omit(cols ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbPermissionDo) Join(table schema.Tabler, on ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbPermissionDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbPermissionDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbPermissionDo) Group(cols ...field.Expr) ITbPermissionDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbPermissionDo) Having(conds ...gen.Condition) ITbPermissionDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbPermissionDo) Limit(limit int) ITbPermissionDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbPermissionDo) Offset(offset int) ITbPermissionDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbPermissionDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbPermissionDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbPermissionDo) Unscoped() ITbPermissionDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbPermissionDo) Create(values ...*model.TbPermission) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbPermissionDo) CreateInBatches(values []*model.TbPermission, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbPermissionDo) Save(values ...*model.TbPermission) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbPermissionDo) First() (*model.TbPermission, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbPermission), nil
+	}
+}
+
+func (t tbPermissionDo) Take() (*model.TbPermission, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbPermission), nil
+	}
+}
+
+func (t tbPermissionDo) Last() (*model.TbPermission, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbPermission), nil
+	}
+}
+
+func (t tbPermissionDo) Find() ([]*model.TbPermission, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbPermission), err
+}
+
+func (t tbPermissionDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbPermission, err error) {
+	buf := make([]*model.TbPermission, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbPermissionDo) FindInBatches(result *[]*model.TbPermission, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbPermissionDo) Attrs(attrs ...field.AssignExpr) ITbPermissionDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbPermissionDo) Assign(attrs ...field.AssignExpr) ITbPermissionDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbPermissionDo) Joins(fields ...field.RelationField) ITbPermissionDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbPermissionDo) Preload(fields ...field.RelationField) ITbPermissionDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbPermissionDo) FirstOrInit() (*model.TbPermission, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbPermission), nil
+	}
+}
+
+func (t tbPermissionDo) FirstOrCreate() (*model.TbPermission, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbPermission), nil
+	}
+}
+
+func (t tbPermissionDo) FindByPage(offset int, limit int) (result []*model.TbPermission, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbPermissionDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbPermissionDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbPermissionDo) Delete(models ...*model.TbPermission) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbPermissionDo) withDO(do gen.Dao) *tbPermissionDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
@@ -0,0 +1,395 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbRole(db *gorm.DB, opts ...gen.DOOption) tbRole {
+	_tbRole := tbRole{}
+
+	_tbRole.tbRoleDo.UseDB(db, opts...)
+	_tbRole.tbRoleDo.UseModel(&model.TbRole{})
+
+	tableName := _tbRole.tbRoleDo.TableName()
+	_tbRole.ALL = field.NewAsterisk(tableName)
+	_tbRole.ID = field.NewUint64(tableName, "id")
+	_tbRole.Code = field.NewString(tableName, "code")
+	_tbRole.Name = field.NewString(tableName, "name")
+	_tbRole.CreateTime = field.NewTime(tableName, "create_time")
+
+	_tbRole.fillFieldMap()
+
+	return _tbRole
+}
+
+type tbRole struct {
+	tbRoleDo
+
+	ALL        field.Asterisk
+	ID         field.Uint64 // 主键
+	Code       field.String // 角色编码：user,merchant,moderator,admin
+	Name       field.String // 角色名称
+	CreateTime field.Time   // 创建时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbRole) Table(newTableName string) *tbRole {
+	t.tbRoleDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbRole) As(alias string) *tbRole {
+	t.tbRoleDo.DO = *(t.tbRoleDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbRole) updateTableName(table string) *tbRole {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.Code = field.NewString(table, "code")
+	t.Name = field.NewString(table, "name")
+	t.CreateTime = field.NewTime(table, "create_time")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbRole) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbRole) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 4)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["code"] = t.Code
+	t.fieldMap["name"] = t.Name
+	t.fieldMap["create_time"] = t.CreateTime
+}
+
+func (t tbRole) clone(db *gorm.DB) tbRole {
+	t.tbRoleDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbRole) replaceDB(db *gorm.DB) tbRole {
+	t.tbRoleDo.ReplaceDB(db)
+	return t
+}
+
+type tbRoleDo struct{ gen.DO }
+
+type ITbRoleDo interface {
+	gen.SubQuery
+	Debug() ITbRoleDo
+	WithContext(ctx context.Context) ITbRoleDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbRoleDo
+	WriteDB() ITbRoleDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbRoleDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbRoleDo
+	Not(conds ...gen.Condition) ITbRoleDo
+	Or(conds ...gen.Condition) ITbRoleDo
+	Select(conds ...field.Expr) ITbRoleDo
+	Where(conds ...gen.Condition) ITbRoleDo
+	Order(conds ...field.Expr) ITbRoleDo
+	Distinct(cols ...field.Expr) ITbRoleDo
+	Omit(cols ...field.Expr) ITbRoleDo
+	Join(table schema.Tabler, on ...field.Expr) ITbRoleDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbRoleDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbRoleDo
+	Group(cols ...field.Expr) ITbRoleDo
+	Having(conds ...gen.Condition) ITbRoleDo
+	Limit(limit int) ITbRoleDo
+	Offset(offset int) ITbRoleDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbRoleDo
+	Unscoped() ITbRoleDo
+	Create(values ...*model.TbRole) error
+	CreateInBatches(values []*model.TbRole, batchSize int) error
+	Save(values ...*model.TbRole) error
+	First() (*model.TbRole, error)
+	Take() (*model.TbRole, error)
+	Last() (*model.TbRole, error)
+	Find() ([]*model.TbRole, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbRole, err error)
+	FindInBatches(result *[]*model.TbRole, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbRole) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbRoleDo
+	Assign(attrs ...field.AssignExpr) ITbRoleDo
+	Joins(fields ...field.RelationField) ITbRoleDo
+	Preload(fields ...field.RelationField) ITbRoleDo
+	FirstOrInit() (*model.TbRole, error)
+	FirstOrCreate() (*model.TbRole, error)
+	FindByPage(offset int, limit int) (result []*model.TbRole, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbRoleDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbRoleDo) Debug() ITbRoleDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbRoleDo) WithContext(ctx context.Context) ITbRoleDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbRoleDo) ReadDB() ITbRoleDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbRoleDo) WriteDB() ITbRoleDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbRoleDo) Session(config *gorm.Session) ITbRoleDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbRoleDo) Clauses(conds ...clause.Expression) ITbRoleDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbRoleDo) Returning(value interface{}, columns ...string) ITbRoleDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbRoleDo) Not(conds ...gen.Condition) ITbRoleDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbRoleDo) Or(conds ...gen.Condition) ITbRoleDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbRoleDo) Select(conds ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbRoleDo) Where(conds ...gen.Condition) ITbRoleDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbRoleDo) Order(conds ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbRoleDo) Distinct(cols ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbRoleDo) Omit(cols ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbRoleDo) Join(table schema.Tabler, on ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbRoleDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbRoleDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbRoleDo) Group(cols ...field.Expr) ITbRoleDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbRoleDo) Having(conds ...gen.Condition) ITbRoleDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbRoleDo) Limit(limit int) ITbRoleDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbRoleDo) Offset(offset int) ITbRoleDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbRoleDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbRoleDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbRoleDo) Unscoped() ITbRoleDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbRoleDo) Create(values ...*model.TbRole) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbRoleDo) CreateInBatches(values []*model.TbRole, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbRoleDo) Save(values ...*model.TbRole) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbRoleDo) First() (*model.TbRole, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRole), nil
+	}
+}
+
+func (t tbRoleDo) Take() (*model.TbRole, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRole), nil
+	}
+}
+
+func (t tbRoleDo) Last() (*model.TbRole, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRole), nil
+	}
+}
+
+func (t tbRoleDo) Find() ([]*model.TbRole, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbRole), err
+}
+
+func (t tbRoleDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbRole, err error) {
+	buf := make([]*model.TbRole, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbRoleDo) FindInBatches(result *[]*model.TbRole, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbRoleDo) Attrs(attrs ...field.AssignExpr) ITbRoleDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbRoleDo) Assign(attrs ...field.AssignExpr) ITbRoleDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbRoleDo) Joins(fields ...field.RelationField) ITbRoleDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbRoleDo) Preload(fields ...field.RelationField) ITbRoleDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbRoleDo) FirstOrInit() (*model.TbRole, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRole), nil
+	}
+}
+
+func (t tbRoleDo) FirstOrCreate() (*model.TbRole, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRole), nil
+	}
+}
+
+func (t tbRoleDo) FindByPage(offset int, limit int) (result []*model.TbRole, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbRoleDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbRoleDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbRoleDo) Delete(models ...*model.TbRole) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbRoleDo) withDO(do gen.Dao) *tbRoleDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
@@ -0,0 +1,391 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbRolePermission(db *gorm.DB, opts ...gen.DOOption) tbRolePermission {
+	_tbRolePermission := tbRolePermission{}
+
+	_tbRolePermission.tbRolePermissionDo.UseDB(db, opts...)
+	_tbRolePermission.tbRolePermissionDo.UseModel(&model.TbRolePermission{})
+
+	tableName := _tbRolePermission.tbRolePermissionDo.TableName()
+	_tbRolePermission.ALL = field.NewAsterisk(tableName)
+	_tbRolePermission.ID = field.NewUint64(tableName, "id")
+	_tbRolePermission.RoleID = field.NewUint64(tableName, "role_id")
+	_tbRolePermission.PermissionID = field.NewUint64(tableName, "permission_id")
+
+	_tbRolePermission.fillFieldMap()
+
+	return _tbRolePermission
+}
+
+type tbRolePermission struct {
+	tbRolePermissionDo
+
+	ALL          field.Asterisk
+	ID           field.Uint64 // 主键
+	RoleID       field.Uint64 // 角色id
+	PermissionID field.Uint64 // 权限id
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbRolePermission) Table(newTableName string) *tbRolePermission {
+	t.tbRolePermissionDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbRolePermission) As(alias string) *tbRolePermission {
+	t.tbRolePermissionDo.DO = *(t.tbRolePermissionDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbRolePermission) updateTableName(table string) *tbRolePermission {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.RoleID = field.NewUint64(table, "role_id")
+	t.PermissionID = field.NewUint64(table, "permission_id")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbRolePermission) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbRolePermission) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 3)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["role_id"] = t.RoleID
+	t.fieldMap["permission_id"] = t.PermissionID
+}
+
+func (t tbRolePermission) clone(db *gorm.DB) tbRolePermission {
+	t.tbRolePermissionDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbRolePermission) replaceDB(db *gorm.DB) tbRolePermission {
+	t.tbRolePermissionDo.ReplaceDB(db)
+	return t
+}
+
+type tbRolePermissionDo struct{ gen.DO }
+
+type ITbRolePermissionDo interface {
+	gen.SubQuery
+	Debug() ITbRolePermissionDo
+	WithContext(ctx context.Context) ITbRolePermissionDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbRolePermissionDo
+	WriteDB() ITbRolePermissionDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbRolePermissionDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbRolePermissionDo
+	Not(conds ...gen.Condition) ITbRolePermissionDo
+	Or(conds ...gen.Condition) ITbRolePermissionDo
+	Select(conds ...field.Expr) ITbRolePermissionDo
+	Where(conds ...gen.Condition) ITbRolePermissionDo
+	Order(conds ...field.Expr) ITbRolePermissionDo
+	Distinct(cols ...field.Expr) ITbRolePermissionDo
+	Omit(cols ...field.Expr) ITbRolePermissionDo
+	Join(table schema.Tabler, on ...field.Expr) ITbRolePermissionDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbRolePermissionDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbRolePermissionDo
+	Group(cols ...field.Expr) ITbRolePermissionDo
+	Having(conds ...gen.Condition) ITbRolePermissionDo
+	Limit(limit int) ITbRolePermissionDo
+	Offset(offset int) ITbRolePermissionDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbRolePermissionDo
+	Unscoped() ITbRolePermissionDo
+	Create(values ...*model.TbRolePermission) error
+	CreateInBatches(values []*model.TbRolePermission, batchSize int) error
+	Save(values ...*model.TbRolePermission) error
+	First() (*model.TbRolePermission, error)
+	Take() (*model.TbRolePermission, error)
+	Last() (*model.TbRolePermission, error)
+	Find() ([]*model.TbRolePermission, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbRolePermission, err error)
+	FindInBatches(result *[]*model.TbRolePermission, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbRolePermission) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbRolePermissionDo
+	Assign(attrs ...field.AssignExpr) ITbRolePermissionDo
+	Joins(fields ...field.RelationField) ITbRolePermissionDo
+	Preload(fields ...field.RelationField) ITbRolePermissionDo
+	FirstOrInit() (*model.TbRolePermission, error)
+	FirstOrCreate() (*model.TbRolePermission, error)
+	FindByPage(offset int, limit int) (result []*model.TbRolePermission, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbRolePermissionDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbRolePermissionDo) Debug() ITbRolePermissionDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbRolePermissionDo) WithContext(ctx context.Context) ITbRolePermissionDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbRolePermissionDo) ReadDB() ITbRolePermissionDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbRolePermissionDo) WriteDB() ITbRolePermissionDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbRolePermissionDo) Session(config *gorm.Session) ITbRolePermissionDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbRolePermissionDo) Clauses(conds ...clause.Expression) ITbRolePermissionDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbRolePermissionDo) Returning(value interface{}, columns ...string) ITbRolePermissionDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbRolePermissionDo) Not(conds ...gen.Condition) ITbRolePermissionDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbRolePermissionDo) Or(conds ...gen.Condition) ITbRolePermissionDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbRolePermissionDo) Select(conds ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbRolePermissionDo) Where(conds ...gen.Condition) ITbRolePermissionDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbRolePermissionDo) Order(conds ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbRolePermissionDo) Distinct(cols ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbRolePermissionDo) Omit(cols ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbRolePermissionDo) Join(table schema.Tabler, on ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbRolePermissionDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbRolePermissionDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbRolePermissionDo) Group(cols ...field.Expr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbRolePermissionDo) Having(conds ...gen.Condition) ITbRolePermissionDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbRolePermissionDo) Limit(limit int) ITbRolePermissionDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbRolePermissionDo) Offset(offset int) ITbRolePermissionDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbRolePermissionDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbRolePermissionDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbRolePermissionDo) Unscoped() ITbRolePermissionDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbRolePermissionDo) Create(values ...*model.TbRolePermission) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbRolePermissionDo) CreateInBatches(values []*model.TbRolePermission, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbRolePermissionDo) Save(values ...*model.TbRolePermission) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbRolePermissionDo) First() (*model.TbRolePermission, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRolePermission), nil
+	}
+}
+
+func (t tbRolePermissionDo) Take() (*model.TbRolePermission, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRolePermission), nil
+	}
+}
+
+func (t tbRolePermissionDo) Last() (*model.TbRolePermission, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRolePermission), nil
+	}
+}
+
+func (t tbRolePermissionDo) Find() ([]*model.TbRolePermission, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbRolePermission), err
+}
+
+func (t tbRolePermissionDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbRolePermission, err error) {
+	buf := make([]*model.TbRolePermission, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbRolePermissionDo) FindInBatches(result *[]*model.TbRolePermission, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbRolePermissionDo) Attrs(attrs ...field.AssignExpr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbRolePermissionDo) Assign(attrs ...field.AssignExpr) ITbRolePermissionDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbRolePermissionDo) Joins(fields ...field.RelationField) ITbRolePermissionDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbRolePermissionDo) Preload(fields ...field.RelationField) ITbRolePermissionDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbRolePermissionDo) FirstOrInit() (*model.TbRolePermission, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRolePermission), nil
+	}
+}
+
+func (t tbRolePermissionDo) FirstOrCreate() (*model.TbRolePermission, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbRolePermission), nil
+	}
+}
+
+func (t tbRolePermissionDo) FindByPage(offset int, limit int) (result []*model.TbRolePermission, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbRolePermissionDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbRolePermissionDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbRolePermissionDo) Delete(models ...*model.TbRolePermission) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbRolePermissionDo) withDO(do gen.Dao) *tbRolePermissionDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
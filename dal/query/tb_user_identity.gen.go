@@ -0,0 +1,403 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbUserIdentity(db *gorm.DB, opts ...gen.DOOption) tbUserIdentity {
+	_tbUserIdentity := tbUserIdentity{}
+
+	_tbUserIdentity.tbUserIdentityDo.UseDB(db, opts...)
+	_tbUserIdentity.tbUserIdentityDo.UseModel(&model.TbUserIdentity{})
+
+	tableName := _tbUserIdentity.tbUserIdentityDo.TableName()
+	_tbUserIdentity.ALL = field.NewAsterisk(tableName)
+	_tbUserIdentity.ID = field.NewUint64(tableName, "id")
+	_tbUserIdentity.UserID = field.NewUint64(tableName, "user_id")
+	_tbUserIdentity.Provider = field.NewString(tableName, "provider")
+	_tbUserIdentity.Subject = field.NewString(tableName, "subject")
+	_tbUserIdentity.Email = field.NewString(tableName, "email")
+	_tbUserIdentity.CreateTime = field.NewTime(tableName, "create_time")
+
+	_tbUserIdentity.fillFieldMap()
+
+	return _tbUserIdentity
+}
+
+type tbUserIdentity struct {
+	tbUserIdentityDo
+
+	ALL        field.Asterisk
+	ID         field.Uint64 // 主键
+	UserID     field.Uint64 // 用户id
+	Provider   field.String // 身份提供方名称
+	Subject    field.String // 身份提供方中的用户标识（id_token的sub）
+	Email      field.String // 身份提供方返回的邮箱
+	CreateTime field.Time   // 绑定时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbUserIdentity) Table(newTableName string) *tbUserIdentity {
+	t.tbUserIdentityDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbUserIdentity) As(alias string) *tbUserIdentity {
+	t.tbUserIdentityDo.DO = *(t.tbUserIdentityDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbUserIdentity) updateTableName(table string) *tbUserIdentity {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.UserID = field.NewUint64(table, "user_id")
+	t.Provider = field.NewString(table, "provider")
+	t.Subject = field.NewString(table, "subject")
+	t.Email = field.NewString(table, "email")
+	t.CreateTime = field.NewTime(table, "create_time")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbUserIdentity) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbUserIdentity) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 6)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["user_id"] = t.UserID
+	t.fieldMap["provider"] = t.Provider
+	t.fieldMap["subject"] = t.Subject
+	t.fieldMap["email"] = t.Email
+	t.fieldMap["create_time"] = t.CreateTime
+}
+
+func (t tbUserIdentity) clone(db *gorm.DB) tbUserIdentity {
+	t.tbUserIdentityDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbUserIdentity) replaceDB(db *gorm.DB) tbUserIdentity {
+	t.tbUserIdentityDo.ReplaceDB(db)
+	return t
+}
+
+type tbUserIdentityDo struct{ gen.DO }
+
+type ITbUserIdentityDo interface {
+	gen.SubQuery
+	Debug() ITbUserIdentityDo
+	WithContext(ctx context.Context) ITbUserIdentityDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbUserIdentityDo
+	WriteDB() ITbUserIdentityDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbUserIdentityDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbUserIdentityDo
+	Not(conds ...gen.Condition) ITbUserIdentityDo
+	Or(conds ...gen.Condition) ITbUserIdentityDo
+	Select(conds ...field.Expr) ITbUserIdentityDo
+	Where(conds ...gen.Condition) ITbUserIdentityDo
+	Order(conds ...field.Expr) ITbUserIdentityDo
+	Distinct(cols ...field.Expr) ITbUserIdentityDo
+	Omit(cols ...field.Expr) ITbUserIdentityDo
+	Join(table schema.Tabler, on ...field.Expr) ITbUserIdentityDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbUserIdentityDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbUserIdentityDo
+	Group(cols ...field.Expr) ITbUserIdentityDo
+	Having(conds ...gen.Condition) ITbUserIdentityDo
+	Limit(limit int) ITbUserIdentityDo
+	Offset(offset int) ITbUserIdentityDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbUserIdentityDo
+	Unscoped() ITbUserIdentityDo
+	Create(values ...*model.TbUserIdentity) error
+	CreateInBatches(values []*model.TbUserIdentity, batchSize int) error
+	Save(values ...*model.TbUserIdentity) error
+	First() (*model.TbUserIdentity, error)
+	Take() (*model.TbUserIdentity, error)
+	Last() (*model.TbUserIdentity, error)
+	Find() ([]*model.TbUserIdentity, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbUserIdentity, err error)
+	FindInBatches(result *[]*model.TbUserIdentity, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbUserIdentity) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbUserIdentityDo
+	Assign(attrs ...field.AssignExpr) ITbUserIdentityDo
+	Joins(fields ...field.RelationField) ITbUserIdentityDo
+	Preload(fields ...field.RelationField) ITbUserIdentityDo
+	FirstOrInit() (*model.TbUserIdentity, error)
+	FirstOrCreate() (*model.TbUserIdentity, error)
+	FindByPage(offset int, limit int) (result []*model.TbUserIdentity, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbUserIdentityDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbUserIdentityDo) Debug() ITbUserIdentityDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbUserIdentityDo) WithContext(ctx context.Context) ITbUserIdentityDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbUserIdentityDo) ReadDB() ITbUserIdentityDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbUserIdentityDo) WriteDB() ITbUserIdentityDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbUserIdentityDo) Session(config *gorm.Session) ITbUserIdentityDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbUserIdentityDo) Clauses(conds ...clause.Expression) ITbUserIdentityDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbUserIdentityDo) Returning(value interface{}, columns ...string) ITbUserIdentityDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbUserIdentityDo) Not(conds ...gen.Condition) ITbUserIdentityDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbUserIdentityDo) Or(conds ...gen.Condition) ITbUserIdentityDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbUserIdentityDo) Select(conds ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbUserIdentityDo) Where(conds ...gen.Condition) ITbUserIdentityDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbUserIdentityDo) Order(conds ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbUserIdentityDo) Distinct(cols ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbUserIdentityDo) Omit(cols ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbUserIdentityDo) Join(table schema.Tabler, on ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbUserIdentityDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbUserIdentityDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbUserIdentityDo) Group(cols ...field.Expr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbUserIdentityDo) Having(conds ...gen.Condition) ITbUserIdentityDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbUserIdentityDo) Limit(limit int) ITbUserIdentityDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbUserIdentityDo) Offset(offset int) ITbUserIdentityDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbUserIdentityDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbUserIdentityDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbUserIdentityDo) Unscoped() ITbUserIdentityDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbUserIdentityDo) Create(values ...*model.TbUserIdentity) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbUserIdentityDo) CreateInBatches(values []*model.TbUserIdentity, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbUserIdentityDo) Save(values ...*model.TbUserIdentity) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbUserIdentityDo) First() (*model.TbUserIdentity, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserIdentity), nil
+	}
+}
+
+func (t tbUserIdentityDo) Take() (*model.TbUserIdentity, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserIdentity), nil
+	}
+}
+
+func (t tbUserIdentityDo) Last() (*model.TbUserIdentity, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserIdentity), nil
+	}
+}
+
+func (t tbUserIdentityDo) Find() ([]*model.TbUserIdentity, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbUserIdentity), err
+}
+
+func (t tbUserIdentityDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbUserIdentity, err error) {
+	buf := make([]*model.TbUserIdentity, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbUserIdentityDo) FindInBatches(result *[]*model.TbUserIdentity, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbUserIdentityDo) Attrs(attrs ...field.AssignExpr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbUserIdentityDo) Assign(attrs ...field.AssignExpr) ITbUserIdentityDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbUserIdentityDo) Joins(fields ...field.RelationField) ITbUserIdentityDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbUserIdentityDo) Preload(fields ...field.RelationField) ITbUserIdentityDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbUserIdentityDo) FirstOrInit() (*model.TbUserIdentity, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserIdentity), nil
+	}
+}
+
+func (t tbUserIdentityDo) FirstOrCreate() (*model.TbUserIdentity, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserIdentity), nil
+	}
+}
+
+func (t tbUserIdentityDo) FindByPage(offset int, limit int) (result []*model.TbUserIdentity, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbUserIdentityDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbUserIdentityDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbUserIdentityDo) Delete(models ...*model.TbUserIdentity) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbUserIdentityDo) withDO(do gen.Dao) *tbUserIdentityDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
@@ -0,0 +1,395 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"xzdp/dal/model"
+)
+
+func newTbUserRole(db *gorm.DB, opts ...gen.DOOption) tbUserRole {
+	_tbUserRole := tbUserRole{}
+
+	_tbUserRole.tbUserRoleDo.UseDB(db, opts...)
+	_tbUserRole.tbUserRoleDo.UseModel(&model.TbUserRole{})
+
+	tableName := _tbUserRole.tbUserRoleDo.TableName()
+	_tbUserRole.ALL = field.NewAsterisk(tableName)
+	_tbUserRole.ID = field.NewUint64(tableName, "id")
+	_tbUserRole.UserID = field.NewUint64(tableName, "user_id")
+	_tbUserRole.RoleID = field.NewUint64(tableName, "role_id")
+	_tbUserRole.CreateTime = field.NewTime(tableName, "create_time")
+
+	_tbUserRole.fillFieldMap()
+
+	return _tbUserRole
+}
+
+type tbUserRole struct {
+	tbUserRoleDo
+
+	ALL        field.Asterisk
+	ID         field.Uint64 // 主键
+	UserID     field.Uint64 // 用户id
+	RoleID     field.Uint64 // 角色id
+	CreateTime field.Time   // 创建时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tbUserRole) Table(newTableName string) *tbUserRole {
+	t.tbUserRoleDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tbUserRole) As(alias string) *tbUserRole {
+	t.tbUserRoleDo.DO = *(t.tbUserRoleDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tbUserRole) updateTableName(table string) *tbUserRole {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint64(table, "id")
+	t.UserID = field.NewUint64(table, "user_id")
+	t.RoleID = field.NewUint64(table, "role_id")
+	t.CreateTime = field.NewTime(table, "create_time")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tbUserRole) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tbUserRole) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 4)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["user_id"] = t.UserID
+	t.fieldMap["role_id"] = t.RoleID
+	t.fieldMap["create_time"] = t.CreateTime
+}
+
+func (t tbUserRole) clone(db *gorm.DB) tbUserRole {
+	t.tbUserRoleDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tbUserRole) replaceDB(db *gorm.DB) tbUserRole {
+	t.tbUserRoleDo.ReplaceDB(db)
+	return t
+}
+
+type tbUserRoleDo struct{ gen.DO }
+
+type ITbUserRoleDo interface {
+	gen.SubQuery
+	Debug() ITbUserRoleDo
+	WithContext(ctx context.Context) ITbUserRoleDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITbUserRoleDo
+	WriteDB() ITbUserRoleDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITbUserRoleDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITbUserRoleDo
+	Not(conds ...gen.Condition) ITbUserRoleDo
+	Or(conds ...gen.Condition) ITbUserRoleDo
+	Select(conds ...field.Expr) ITbUserRoleDo
+	Where(conds ...gen.Condition) ITbUserRoleDo
+	Order(conds ...field.Expr) ITbUserRoleDo
+	Distinct(cols ...field.Expr) ITbUserRoleDo
+	Omit(cols ...field.Expr) ITbUserRoleDo
+	Join(table schema.Tabler, on ...field.Expr) ITbUserRoleDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITbUserRoleDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITbUserRoleDo
+	Group(cols ...field.Expr) ITbUserRoleDo
+	Having(conds ...gen.Condition) ITbUserRoleDo
+	Limit(limit int) ITbUserRoleDo
+	Offset(offset int) ITbUserRoleDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITbUserRoleDo
+	Unscoped() ITbUserRoleDo
+	Create(values ...*model.TbUserRole) error
+	CreateInBatches(values []*model.TbUserRole, batchSize int) error
+	Save(values ...*model.TbUserRole) error
+	First() (*model.TbUserRole, error)
+	Take() (*model.TbUserRole, error)
+	Last() (*model.TbUserRole, error)
+	Find() ([]*model.TbUserRole, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbUserRole, err error)
+	FindInBatches(result *[]*model.TbUserRole, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.TbUserRole) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITbUserRoleDo
+	Assign(attrs ...field.AssignExpr) ITbUserRoleDo
+	Joins(fields ...field.RelationField) ITbUserRoleDo
+	Preload(fields ...field.RelationField) ITbUserRoleDo
+	FirstOrInit() (*model.TbUserRole, error)
+	FirstOrCreate() (*model.TbUserRole, error)
+	FindByPage(offset int, limit int) (result []*model.TbUserRole, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITbUserRoleDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tbUserRoleDo) Debug() ITbUserRoleDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tbUserRoleDo) WithContext(ctx context.Context) ITbUserRoleDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tbUserRoleDo) ReadDB() ITbUserRoleDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tbUserRoleDo) WriteDB() ITbUserRoleDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tbUserRoleDo) Session(config *gorm.Session) ITbUserRoleDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tbUserRoleDo) Clauses(conds ...clause.Expression) ITbUserRoleDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tbUserRoleDo) Returning(value interface{}, columns ...string) ITbUserRoleDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tbUserRoleDo) Not(conds ...gen.Condition) ITbUserRoleDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tbUserRoleDo) Or(conds ...gen.Condition) ITbUserRoleDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tbUserRoleDo) Select(conds ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tbUserRoleDo) Where(conds ...gen.Condition) ITbUserRoleDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tbUserRoleDo) Order(conds ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tbUserRoleDo) Distinct(cols ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tbUserRoleDo) Omit(cols ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tbUserRoleDo) Join(table schema.Tabler, on ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tbUserRoleDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tbUserRoleDo) RightJoin(table schema.Tabler, on ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tbUserRoleDo) Group(cols ...field.Expr) ITbUserRoleDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tbUserRoleDo) Having(conds ...gen.Condition) ITbUserRoleDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tbUserRoleDo) Limit(limit int) ITbUserRoleDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tbUserRoleDo) Offset(offset int) ITbUserRoleDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tbUserRoleDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITbUserRoleDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tbUserRoleDo) Unscoped() ITbUserRoleDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tbUserRoleDo) Create(values ...*model.TbUserRole) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tbUserRoleDo) CreateInBatches(values []*model.TbUserRole, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tbUserRoleDo) Save(values ...*model.TbUserRole) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tbUserRoleDo) First() (*model.TbUserRole, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserRole), nil
+	}
+}
+
+func (t tbUserRoleDo) Take() (*model.TbUserRole, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserRole), nil
+	}
+}
+
+func (t tbUserRoleDo) Last() (*model.TbUserRole, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserRole), nil
+	}
+}
+
+func (t tbUserRoleDo) Find() ([]*model.TbUserRole, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.TbUserRole), err
+}
+
+func (t tbUserRoleDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.TbUserRole, err error) {
+	buf := make([]*model.TbUserRole, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tbUserRoleDo) FindInBatches(result *[]*model.TbUserRole, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tbUserRoleDo) Attrs(attrs ...field.AssignExpr) ITbUserRoleDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tbUserRoleDo) Assign(attrs ...field.AssignExpr) ITbUserRoleDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tbUserRoleDo) Joins(fields ...field.RelationField) ITbUserRoleDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tbUserRoleDo) Preload(fields ...field.RelationField) ITbUserRoleDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tbUserRoleDo) FirstOrInit() (*model.TbUserRole, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserRole), nil
+	}
+}
+
+func (t tbUserRoleDo) FirstOrCreate() (*model.TbUserRole, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.TbUserRole), nil
+	}
+}
+
+func (t tbUserRoleDo) FindByPage(offset int, limit int) (result []*model.TbUserRole, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tbUserRoleDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tbUserRoleDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tbUserRoleDo) Delete(models ...*model.TbUserRole) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tbUserRoleDo) withDO(do gen.Dao) *tbUserRoleDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
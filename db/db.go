@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var errNotInitialized = errors.New("not initialized")
+
+// Close 退出时关闭MySQL连接池和Redis客户端
+func Close() error {
+	var errs []error
+	if DBEngine != nil {
+		sqlDB, err := DBEngine.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		errs = append(errs, err, closeReplicas())
+	}
+	if RedisDb != nil {
+		errs = append(errs, RedisDb.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// PingMySQL 就绪检查：MySQL是否可用
+func PingMySQL(ctx context.Context) error {
+	if DBEngine == nil {
+		return errNotInitialized
+	}
+	sqlDB, err := DBEngine.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// PingRedis 就绪检查：Redis是否可用
+func PingRedis(ctx context.Context) error {
+	if RedisDb == nil {
+		return errNotInitialized
+	}
+	return RedisDb.Ping(ctx).Err()
+}
@@ -0,0 +1,338 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 数据库版本迁移，SQL文件编译进二进制
+// 文件名格式为 {版本号}_{名称}.up.sql 和 {版本号}_{名称}.down.sql，版本号递增，已经发布的文件不要修改，修改表结构时新增一个版本
+// 已执行的版本记录在 schema_migrations 中，执行到一半失败时该版本标记为dirty，需要人工修复后用 force 指定当前版本
+
+//go:embed sql/*.sql
+var sqlFS embed.FS
+
+const (
+	versionTable = "schema_migrations"
+	// 多个实例同时执行迁移时只有一个能拿到锁
+	lockName    = "xzdp:migrate"
+	lockTimeout = 10 //秒
+)
+
+var (
+	ErrDirty    = errors.New("database is dirty")
+	ErrOutdated = errors.New("database schema is out of date")
+
+	fileRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	// 语句以行尾的分号结束
+	stmtSep = regexp.MustCompile(`;\s*(\n|$)`)
+)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+// 读取所有迁移，按版本号排序
+func load() ([]Migration, error) {
+	files, err := fs.Glob(sqlFS, "sql/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[uint64]*Migration{}
+	for _, file := range files {
+		m := fileRe.FindStringSubmatch(strings.TrimPrefix(file, "sql/"))
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", file)
+		}
+		version, _ := strconv.ParseUint(m[1], 10, 64)
+		content, err := sqlFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// 已执行的版本
+type applied struct {
+	dirty     bool
+	appliedAt time.Time
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `"+versionTable+"` ("+
+		"`version` bigint unsigned NOT NULL COMMENT '版本号',"+
+		"`name` varchar(128) NOT NULL COMMENT '名称',"+
+		"`dirty` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否执行到一半失败',"+
+		"`applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '执行时间',"+
+		"PRIMARY KEY (`version`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	return err
+}
+
+func getApplied(ctx context.Context, q interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}) (map[uint64]applied, error) {
+	rows, err := q.QueryContext(ctx, "SELECT `version`, `dirty`, `applied_at` FROM `"+versionTable+"`")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[uint64]applied{}
+	for rows.Next() {
+		var version uint64
+		var a applied
+		if err = rows.Scan(&version, &a.dirty, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		res[version] = a
+	}
+	return res, rows.Err()
+}
+
+func dirtyVersion(versions map[uint64]applied) (uint64, bool) {
+	for v, a := range versions {
+		if a.dirty {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// 拿到锁之后在同一个连接上执行fn，MySQL的锁是连接级别的
+func withLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var got sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&got); err != nil {
+		return err
+	}
+	if got.Int64 != 1 {
+		return errors.New("another migration is running")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	if err = ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// 逐条执行，DDL在MySQL中会隐式提交，所以不放在事务中
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range stmtSep.Split(script, -1) {
+		if strings.TrimSpace(stripComments(stmt)) == "" {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stripComments(stmt string) string {
+	lines := strings.Split(stmt, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// Up 执行所有未执行的迁移，返回执行了的迁移
+func Up(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withLock(ctx, db, func(conn *sql.Conn) error {
+		versions, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if v, ok := dirtyVersion(versions); ok {
+			return fmt.Errorf("%w: version %d", ErrDirty, v)
+		}
+		for _, m := range migrations {
+			if _, ok := versions[m.Version]; ok {
+				continue
+			}
+			//1.先标记为dirty，执行成功后再清除
+			_, err = conn.ExecContext(ctx, "INSERT INTO `"+versionTable+"` (`version`, `name`, `dirty`) VALUES (?, ?, 1)", m.Version, m.Name)
+			if err != nil {
+				return err
+			}
+			if err = execScript(ctx, conn, m.Up); err != nil {
+				return fmt.Errorf("migration %d_%s failed, database is dirty: %w", m.Version, m.Name, err)
+			}
+			_, err = conn.ExecContext(ctx, "UPDATE `"+versionTable+"` SET `dirty` = 0, `applied_at` = CURRENT_TIMESTAMP WHERE `version` = ?", m.Version)
+			if err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 从最新的版本开始回滚steps个迁移，返回回滚了的迁移
+func Down(ctx context.Context, db *sql.DB, steps int) ([]Migration, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withLock(ctx, db, func(conn *sql.Conn) error {
+		versions, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if v, ok := dirtyVersion(versions); ok {
+			return fmt.Errorf("%w: version %d", ErrDirty, v)
+		}
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := migrations[i]
+			if _, ok := versions[m.Version]; !ok {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+			}
+			_, err = conn.ExecContext(ctx, "UPDATE `"+versionTable+"` SET `dirty` = 1 WHERE `version` = ?", m.Version)
+			if err != nil {
+				return err
+			}
+			if err = execScript(ctx, conn, m.Down); err != nil {
+				return fmt.Errorf("rollback of %d_%s failed, database is dirty: %w", m.Version, m.Name, err)
+			}
+			_, err = conn.ExecContext(ctx, "DELETE FROM `"+versionTable+"` WHERE `version` = ?", m.Version)
+			if err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Force 人工修复后把数据库标记为已经执行到version（包括version），之后的版本标记为未执行，不执行任何SQL
+// version为0表示一个都没有执行
+func Force(ctx context.Context, db *sql.DB, version uint64) error {
+	migrations, err := load()
+	if err != nil {
+		return err
+	}
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM `"+versionTable+"`"); err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if m.Version > version {
+				break
+			}
+			_, err := conn.ExecContext(ctx, "INSERT INTO `"+versionTable+"` (`version`, `name`) VALUES (?, ?)", m.Version, m.Name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetStatus 所有迁移的执行情况
+func GetStatus(ctx context.Context, db *sql.DB) ([]Status, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+	var res []Status
+	err = withLock(ctx, db, func(conn *sql.Conn) error {
+		versions, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			a, ok := versions[m.Version]
+			res = append(res, Status{Version: m.Version, Name: m.Name, Applied: ok, Dirty: a.dirty, AppliedAt: a.appliedAt})
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Check 启动时检查数据库是否已经执行了所有迁移，没有时返回ErrOutdated，不会修改数据库
+func Check(ctx context.Context, db *sql.DB) error {
+	migrations, err := load()
+	if err != nil {
+		return err
+	}
+	versions, err := getApplied(ctx, db)
+	if err != nil {
+		var exists int
+		if db.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?",
+			versionTable).Scan(&exists) == nil && exists == 0 {
+			return fmt.Errorf("%w: %s not found", ErrOutdated, versionTable)
+		}
+		return err
+	}
+	if v, ok := dirtyVersion(versions); ok {
+		return fmt.Errorf("%w: version %d", ErrDirty, v)
+	}
+	var pending []string
+	for _, m := range migrations {
+		if _, ok := versions[m.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%d_%s", m.Version, m.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending %s", ErrOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+-- 基础表：用户、商户、优惠券、订单、博客、关注、签到
+
+CREATE TABLE `tb_user` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `phone` varchar(11) NOT NULL COMMENT '手机号码',
+  `password` varchar(128) DEFAULT '' COMMENT '密码，加密存储',
+  `nick_name` varchar(32) DEFAULT '' COMMENT '昵称，默认是用户id',
+  `icon` varchar(255) DEFAULT '' COMMENT '人物头像',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_phone` (`phone`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_user_info` (
+  `user_id` bigint unsigned NOT NULL COMMENT '主键，用户id',
+  `city` varchar(64) DEFAULT '' COMMENT '城市名称',
+  `introduce` varchar(128) DEFAULT NULL COMMENT '个人介绍，不要超过128个字符',
+  `fans` int unsigned DEFAULT 0 COMMENT '粉丝数量',
+  `followee` int unsigned DEFAULT 0 COMMENT '关注的人的数量',
+  `gender` tinyint unsigned DEFAULT 0 COMMENT '性别，0：男，1：女',
+  `birthday` date DEFAULT NULL COMMENT '生日',
+  `credits` int unsigned DEFAULT 0 COMMENT '积分',
+  `level` tinyint unsigned DEFAULT 0 COMMENT '会员级别，0~9级,0代表未开通会员',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_shop_type` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `name` varchar(32) DEFAULT NULL COMMENT '类型名称',
+  `icon` varchar(255) DEFAULT NULL COMMENT '图标',
+  `sort` int unsigned DEFAULT NULL COMMENT '顺序',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_shop` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `name` varchar(128) NOT NULL COMMENT '商铺名称',
+  `type_id` bigint unsigned NOT NULL COMMENT '商铺类型的id',
+  `images` varchar(1024) NOT NULL COMMENT '商铺图片，多个图片以'',''隔开',
+  `area` varchar(128) DEFAULT NULL COMMENT '商圈，例如陆家嘴',
+  `address` varchar(255) NOT NULL COMMENT '地址',
+  `x` double unsigned NOT NULL COMMENT '经度',
+  `y` double unsigned NOT NULL COMMENT '维度',
+  `avg_price` bigint unsigned DEFAULT NULL COMMENT '均价，取整数',
+  `sold` int(10) unsigned zerofill NOT NULL COMMENT '销量',
+  `comments` int(10) unsigned zerofill NOT NULL COMMENT '评论数量',
+  `score` int(2) unsigned zerofill NOT NULL COMMENT '评分，1~5分，乘10保存，避免小数',
+  `open_hours` varchar(32) DEFAULT NULL COMMENT '营业时间，例如 10:00-22:00',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_type_id` (`type_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_voucher` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `shop_id` bigint unsigned DEFAULT NULL COMMENT '商铺id',
+  `title` varchar(255) NOT NULL COMMENT '代金券标题',
+  `sub_title` varchar(255) DEFAULT NULL COMMENT '副标题',
+  `rules` varchar(1024) DEFAULT NULL COMMENT '使用规则',
+  `pay_value` bigint unsigned NOT NULL COMMENT '支付金额，单位是分。例如200代表2元',
+  `actual_value` bigint NOT NULL COMMENT '抵扣金额，单位是分。例如200代表2元',
+  `type` tinyint unsigned NOT NULL DEFAULT 0 COMMENT '0,普通券；1,秒杀券',
+  `status` tinyint unsigned NOT NULL DEFAULT 1 COMMENT '1,上架; 2,下架; 3,过期',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_shop_id` (`shop_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_seckill_voucher` (
+  `voucher_id` bigint unsigned NOT NULL COMMENT '关联的优惠券的id',
+  `stock` int NOT NULL COMMENT '库存',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `begin_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '生效时间',
+  `end_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '失效时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`voucher_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='秒杀优惠券表，与优惠券是一对一关系';
+
+-- 每个用户每张优惠券只能下一单，唯一索引兜底防止重复下单
+CREATE TABLE `tb_voucher_order` (
+  `id` bigint NOT NULL COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '下单的用户id',
+  `voucher_id` bigint unsigned NOT NULL COMMENT '购买的代金券id',
+  `pay_type` tinyint unsigned NOT NULL DEFAULT 1 COMMENT '支付方式 1：余额支付；2：支付宝；3：微信',
+  `status` tinyint unsigned NOT NULL DEFAULT 1 COMMENT '订单状态，1：未支付；2：已支付；3：已核销；4：已取消；5：退款中；6：已退款',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下单时间',
+  `pay_time` timestamp NULL DEFAULT NULL COMMENT '支付时间',
+  `use_time` timestamp NULL DEFAULT NULL COMMENT '核销时间',
+  `refund_time` timestamp NULL DEFAULT NULL COMMENT '退款时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_voucher` (`user_id`, `voucher_id`),
+  KEY `idx_voucher_id` (`voucher_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_blog` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `shop_id` bigint NOT NULL COMMENT '商户id',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `title` varchar(255) NOT NULL COMMENT '标题',
+  `images` varchar(2048) NOT NULL COMMENT '探店的照片，最多9张，多张以'',''隔开',
+  `content` varchar(2048) NOT NULL COMMENT '探店的文字描述',
+  `liked` int unsigned DEFAULT 0 COMMENT '点赞数量',
+  `comments` int unsigned DEFAULT 0 COMMENT '评论数量',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_shop_id` (`shop_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_blog_comments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `blog_id` bigint unsigned NOT NULL COMMENT '探店id',
+  `parent_id` bigint unsigned NOT NULL COMMENT '关联的1级评论id，如果是一级评论，则值为0',
+  `answer_id` bigint unsigned NOT NULL COMMENT '回复的评论id',
+  `content` varchar(255) NOT NULL COMMENT '回复的内容',
+  `liked` int unsigned DEFAULT 0 COMMENT '点赞数',
+  `status` tinyint unsigned DEFAULT 0 COMMENT '状态，0：正常，1：被举报，2：禁止查看',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_blog_id` (`blog_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_follow` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `follow_user_id` bigint unsigned NOT NULL COMMENT '关联的用户id',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_follow` (`user_id`, `follow_user_id`),
+  KEY `idx_follow_user_id` (`follow_user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tb_sign` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `year` year NOT NULL COMMENT '签到的年',
+  `month` tinyint NOT NULL COMMENT '签到的月',
+  `date` date NOT NULL COMMENT '签到的日期',
+  `is_backup` tinyint unsigned DEFAULT 0 COMMENT '是否补签',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_date` (`user_id`, `date`),
+  KEY `idx_user_month` (`user_id`, `year`, `month`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
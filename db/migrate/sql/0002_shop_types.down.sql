@@ -0,0 +1,2 @@
+-- 不删除商户类型：up 使用 INSERT IGNORE，无法区分哪些记录是这个版本插入的，哪些是旧脚本建库时就有的，
+-- 删除会连带删掉已有的数据；回滚到 0001 之后再执行 up 会跳过已经存在的记录
@@ -0,0 +1,13 @@
+-- 商户类型，图标在前端的 imgs/types 目录下
+-- 用旧脚本建的库中已经有这些商户类型，force 1 之后执行 up 时跳过已经存在的记录，不覆盖修改过的名称和图标
+INSERT IGNORE INTO `tb_shop_type` (`id`, `name`, `icon`, `sort`) VALUES
+  (1, '美食', '/types/ms.png', 1),
+  (2, 'KTV', '/types/KTV.png', 2),
+  (3, '丽人·美发', '/types/lrmf.png', 3),
+  (4, '健身运动', '/types/jsyd.png', 10),
+  (5, '按摩·足疗', '/types/amzl.png', 5),
+  (6, '美容SPA', '/types/spa.png', 6),
+  (7, '亲子游乐', '/types/qzyl.png', 7),
+  (8, '酒吧', '/types/jiuba.png', 8),
+  (9, '轰趴馆', '/types/hpg.png', 9),
+  (10, '美睫·美甲', '/types/mjmj.png', 4);
@@ -0,0 +1,12 @@
+-- 积分流水，按用户游标分页查询
+CREATE TABLE `tb_credit_log` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `amount` int NOT NULL COMMENT '积分变动，正数为获得，负数为扣除',
+  `balance` int unsigned NOT NULL COMMENT '变动后的积分',
+  `reason` varchar(32) NOT NULL COMMENT '积分来源：sign,streak,blog,liked',
+  `ref_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '关联的业务id，例如博客id',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
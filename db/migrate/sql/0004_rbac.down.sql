@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `tb_user_role`;
+DROP TABLE IF EXISTS `tb_role_permission`;
+DROP TABLE IF EXISTS `tb_permission`;
+DROP TABLE IF EXISTS `tb_role`;
@@ -0,0 +1,12 @@
+-- 注销申请，每个用户只保留一条
+CREATE TABLE `tb_account_deletion` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `status` tinyint unsigned NOT NULL COMMENT '状态：0等待注销，1已注销',
+  `purge_time` timestamp NOT NULL COMMENT '冷静期结束、执行注销的时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '申请时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`),
+  KEY `idx_status_purge_time` (`status`, `purge_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
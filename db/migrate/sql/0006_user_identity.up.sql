@@ -0,0 +1,12 @@
+-- 第三方登录的身份，一个用户在每个身份提供方只能绑定一个身份
+CREATE TABLE `tb_user_identity` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户id',
+  `provider` varchar(32) NOT NULL COMMENT '身份提供方名称',
+  `subject` varchar(255) NOT NULL COMMENT '身份提供方中的用户标识（id_token的sub）',
+  `email` varchar(255) DEFAULT NULL COMMENT '身份提供方返回的邮箱',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '绑定时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_provider_subject` (`provider`, `subject`),
+  UNIQUE KEY `uk_user_provider` (`user_id`, `provider`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
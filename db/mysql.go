@@ -1,23 +1,36 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"xzdp/config"
 	"xzdp/dal/query"
+	"xzdp/pkg/metrics"
+	"xzdp/pkg/tracing"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 var DBEngine *gorm.DB
 
-func NewMySQL(mysqlCfg *config.MysqlSetting) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(`%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=true&loc=Local`,
+func dsn(mysqlCfg *config.MysqlSetting, host string) string {
+	return fmt.Sprintf(`%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=true&loc=Local`,
 		mysqlCfg.UserName,
 		mysqlCfg.Password,
-		mysqlCfg.Host,
+		host,
 		mysqlCfg.DbName)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+}
+
+func NewMySQL(mysqlCfg *config.MysqlSetting) (*gorm.DB, error) {
+	db, err := gorm.Open(mysql.Open(dsn(mysqlCfg, mysqlCfg.Host)), &gorm.Config{
+		TranslateError: true, //把唯一索引冲突等数据库错误转换成gorm.ErrDuplicatedKey
+		//从库会沿用这份配置，启动时某个从库不可用不应该导致启动失败，所以只主动ping主库
+		DisableAutomaticPing: true,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -26,9 +39,107 @@ func NewMySQL(mysqlCfg *config.MysqlSetting) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = sqlDB.Ping(); err != nil {
+		return nil, err
+	}
 	sqlDB.SetMaxOpenConns(mysqlCfg.MaxOpenConns) //设置数据库连接池最大连接数
 	sqlDB.SetMaxIdleConns(mysqlCfg.MaxIdleConns) //连接池最大允许的空闲连接数，如果没有sql任务需要执行的连接数大于MaxIdleConns，超过的连接会被连接池关闭
 
+	//链路追踪
+	if err = db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	//统计每条SQL的耗时和连接池状态
+	if err = db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	if err = metrics.RegisterDBPool(sqlDB, mysqlCfg.DbName); err != nil {
+		return nil, err
+	}
+	//读写分离，插件要在链路追踪和指标之后注册，切换连接池时SQL的span和耗时照常记录
+	if err = useReplicas(db, sqlDB, mysqlCfg); err != nil {
+		return nil, err
+	}
+
 	query.SetDefault(db) //设置了才能使用query包
 	return db, nil
 }
+
+// CreateDatabase 数据库不存在时创建，执行迁移前调用
+func CreateDatabase(mysqlCfg *config.MysqlSetting) error {
+	if mysqlCfg.DbName == "" || strings.Contains(mysqlCfg.DbName, "`") {
+		return fmt.Errorf("invalid database name %q", mysqlCfg.DbName)
+	}
+	server := *mysqlCfg
+	server.DbName = ""
+	conn, err := sql.Open("mysql", dsn(&server, server.Host))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Exec("CREATE DATABASE IF NOT EXISTS `" + mysqlCfg.DbName + "` DEFAULT CHARACTER SET utf8mb4")
+	return err
+}
+
+// 配置了从库时注册dbresolver
+func useReplicas(db *gorm.DB, sqlDB *sql.DB, mysqlCfg *config.MysqlSetting) error {
+	if len(mysqlCfg.Replicas) == 0 {
+		return nil
+	}
+	dialectors := make([]gorm.Dialector, 0, len(mysqlCfg.Replicas)+1)
+	for _, host := range mysqlCfg.Replicas {
+		dialectors = append(dialectors, mysql.New(mysql.Config{DSN: dsn(mysqlCfg, host), SkipInitializeWithVersion: true}))
+	}
+	//兜底用的主库，直接使用主库的连接池，不会建立新的连接
+	dialectors = append(dialectors, mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}))
+
+	policy := &replicaPolicy{replicas: make(map[gorm.ConnPool]string, len(mysqlCfg.Replicas))}
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: policy}).
+		SetMaxOpenConns(mysqlCfg.MaxOpenConns).
+		SetMaxIdleConns(mysqlCfg.MaxIdleConns)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+	//按注册的顺序取出从库的连接池，第一个是主库
+	var pools []*sql.DB
+	err := resolver.Call(func(pool gorm.ConnPool) error {
+		if p, ok := pool.(*sql.DB); ok && p != sqlDB {
+			pools = append(pools, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, pool := range pools {
+		policy.replicas[pool] = mysqlCfg.Replicas[i]
+		if err = metrics.RegisterDBPool(pool, mysqlCfg.DbName+"@"+mysqlCfg.Replicas[i]); err != nil {
+			return err
+		}
+	}
+	//启动时先检查一次，不可用的从库直接摘除
+	policy.check(context.Background())
+	replicas = policy
+	return nil
+}
+
+// SetPoolSize 配置热加载后调整连接池大小（从库使用相同的大小），已经建立的连接不受影响
+func SetPoolSize(mysqlCfg *config.MysqlSetting) error {
+	if DBEngine == nil {
+		return errNotInitialized
+	}
+	sqlDB, err := DBEngine.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(mysqlCfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(mysqlCfg.MaxIdleConns)
+	if replicas != nil {
+		for pool := range replicas.replicas {
+			db := pool.(*sql.DB)
+			db.SetMaxOpenConns(mysqlCfg.MaxOpenConns)
+			db.SetMaxIdleConns(mysqlCfg.MaxIdleConns)
+		}
+	}
+	return nil
+}
@@ -2,22 +2,154 @@ package db
 
 import (
 	"context"
-	"github.com/go-redis/redis/v8"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
 	"xzdp/config" // 你的配置包
+	"xzdp/pkg/metrics"
+	"xzdp/pkg/tracing"
+
+	"github.com/go-redis/redis/v8"
 )
 
-var RedisDb *redis.Client
+// 单机、哨兵和集群三种模式的客户端都实现了UniversalClient
+// 集群模式下一个脚本或事务中的key必须在同一个slot，需要一起操作的key用相同的hash tag，例如 seckill:{voucherId}:stock
+var RedisDb redis.UniversalClient
 
-func NewRedisClient(configRedis *config.RedisSetting) (*redis.Client, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     configRedis.Host,     //自己的redis实例的ip和port
-		Password: configRedis.Password, //密码，有设置的话，就需要填写
-		PoolSize: configRedis.PoolSize, //最大的可连接数量
-	})
-	_, err := client.Ping(context.Background()).Result() //测试ping
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+func NewRedisClient(configRedis *config.RedisSetting) (redis.UniversalClient, error) {
+	tlsConfig, err := newRedisTLSConfig(&configRedis.TLS)
 	if err != nil {
 		return nil, err
 	}
-	// fmt.Println("redis测试: ", val)
+	var client redis.UniversalClient
+	switch strings.ToLower(configRedis.Mode) {
+	case "", RedisModeStandalone:
+		client = redis.NewClient(&redis.Options{
+			Addr:            configRedis.Host,     //自己的redis实例的ip和port
+			Username:        configRedis.Username, //ACL用户名，没有启用ACL时为空
+			Password:        configRedis.Password, //密码，有设置的话，就需要填写
+			DB:              configRedis.DB,
+			PoolSize:        configRedis.PoolSize, //最大的可连接数量
+			MinIdleConns:    configRedis.MinIdleConns,
+			DialTimeout:     configRedis.DialTimeout,
+			ReadTimeout:     configRedis.ReadTimeout,
+			WriteTimeout:    configRedis.WriteTimeout,
+			PoolTimeout:     configRedis.PoolTimeout,
+			MaxRetries:      configRedis.MaxRetries,
+			MinRetryBackoff: configRedis.MinRetryBackoff,
+			MaxRetryBackoff: configRedis.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		})
+	case RedisModeSentinel:
+		//通过哨兵找到主节点，主从切换后自动连接新的主节点
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       configRedis.MasterName,
+			SentinelAddrs:    configRedis.Addrs,
+			SentinelPassword: configRedis.SentinelPassword,
+			Username:         configRedis.Username,
+			Password:         configRedis.Password,
+			DB:               configRedis.DB,
+			PoolSize:         configRedis.PoolSize,
+			MinIdleConns:     configRedis.MinIdleConns,
+			DialTimeout:      configRedis.DialTimeout,
+			ReadTimeout:      configRedis.ReadTimeout,
+			WriteTimeout:     configRedis.WriteTimeout,
+			PoolTimeout:      configRedis.PoolTimeout,
+			MaxRetries:       configRedis.MaxRetries,
+			MinRetryBackoff:  configRedis.MinRetryBackoff,
+			MaxRetryBackoff:  configRedis.MaxRetryBackoff,
+			TLSConfig:        tlsConfig,
+		})
+	case RedisModeCluster:
+		//PoolSize是每个节点的连接数
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           configRedis.Addrs,
+			Username:        configRedis.Username,
+			Password:        configRedis.Password,
+			PoolSize:        configRedis.PoolSize,
+			MinIdleConns:    configRedis.MinIdleConns,
+			DialTimeout:     configRedis.DialTimeout,
+			ReadTimeout:     configRedis.ReadTimeout,
+			WriteTimeout:    configRedis.WriteTimeout,
+			PoolTimeout:     configRedis.PoolTimeout,
+			MaxRetries:      configRedis.MaxRetries,
+			MinRetryBackoff: configRedis.MinRetryBackoff,
+			MaxRetryBackoff: configRedis.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		})
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", configRedis.Mode)
+	}
+	client.AddHook(tracing.RedisHook())                 //链路追踪
+	_, err = client.Ping(context.Background()).Result() //测试ping
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	//连接池指标
+	if err = metrics.RegisterRedisPool(client); err != nil {
+		client.Close()
+		return nil, err
+	}
 	return client, err
 }
+
+// 没有启用TLS时返回nil
+func newRedisTLSConfig(setting *config.RedisTLSSetting) (*tls.Config, error) {
+	if !setting.Enable {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         setting.ServerName,
+		InsecureSkipVerify: setting.InsecureSkipVerify, //只用于测试环境
+	}
+	if setting.CAFile != "" {
+		pem, err := os.ReadFile(setting.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", setting.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	//双向认证
+	if setting.CertFile != "" || setting.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(setting.CertFile, setting.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// ScanKeys 遍历匹配match的key，集群模式下会并发遍历所有主节点，fn需要能并发调用
+// fn返回错误时停止遍历并返回这个错误
+func ScanKeys(ctx context.Context, match string, fn func(key string) error) error {
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		iter := client.Scan(ctx, 0, match, 100).Iterator()
+		for iter.Next(ctx) {
+			if err := fn(iter.Val()); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+	if cluster, ok := RedisDb.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	}
+	return scan(ctx, RedisDb)
+}
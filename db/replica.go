@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"xzdp/config"
+
+	"gorm.io/gorm"
+)
+
+// 读写分离：gen生成的查询默认读从库、写主库，事务中的语句都在主库执行
+// 刚写入就要读的地方用 WriteDB() 强制读主库，例如 query.TbUser.WithContext(ctx).WriteDB().Where(...).First()
+
+const (
+	defaultReplicaCheckInterval = 10 * time.Second
+	replicaPingTimeout          = time.Second
+)
+
+// 从库的负载均衡策略：轮询可用的从库，不可用的从库不再分配读请求，全部不可用时读主库
+// 注册到dbresolver的从库列表最后一个是主库的连接池，只用于兜底
+// （dbresolver在只有一个从库时不会调用Policy，加上主库后一个从库的情况也能摘除）
+type replicaPolicy struct {
+	replicas map[gorm.ConnPool]string //从库连接池 -> 地址
+	down     sync.Map                 //不可用的从库连接池
+	next     atomic.Uint64
+}
+
+var replicas *replicaPolicy
+
+func (p *replicaPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	n := uint64(len(pools) - 1)
+	for range n {
+		pool := pools[p.next.Add(1)%n]
+		if _, down := p.down.Load(pool); !down {
+			return pool
+		}
+	}
+	return pools[n]
+}
+
+// 检查所有从库，状态变化时记录日志
+func (p *replicaPolicy) check(ctx context.Context) {
+	for pool, host := range p.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := pool.(*sql.DB).PingContext(pingCtx)
+		cancel()
+		if err != nil {
+			if _, loaded := p.down.LoadOrStore(pool, struct{}{}); !loaded {
+				slog.ErrorContext(ctx, "MySQL从库不可用，不再分配读请求", "host", host, "err", err)
+			}
+			continue
+		}
+		if _, loaded := p.down.LoadAndDelete(pool); loaded {
+			slog.InfoContext(ctx, "MySQL从库已恢复", "host", host)
+		}
+	}
+}
+
+// StartReplicaChecker 启动从库健康检查协程，没有配置从库时不启动，ctx取消时退出，退出后调用wg.Done
+func StartReplicaChecker(ctx context.Context, wg *sync.WaitGroup) {
+	if replicas == nil {
+		return
+	}
+	interval := config.MysqlOption.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = defaultReplicaCheckInterval
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				replicas.check(ctx)
+			}
+		}
+	}()
+}
+
+// 关闭从库连接池
+func closeReplicas() error {
+	if replicas == nil {
+		return nil
+	}
+	var errs []error
+	for pool := range replicas.replicas {
+		errs = append(errs, pool.(*sql.DB).Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,336 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+
+	"gorm.io/gorm/clause"
+)
+
+// 生成压测和本地开发用的数据，同一个Seed生成的数据相同（时间相对于执行时间）
+// 用户按手机号去重，重复执行时已有的用户会被复用；商户、优惠券和博客每次都会追加
+
+type Options struct {
+	Seed            uint64
+	Users           int
+	FollowsPerUser  int //每个用户关注的人数
+	Shops           int
+	VouchersPerShop int //每个商户的普通券数量
+	SeckillVouchers int //秒杀券数量，随机分配给商户
+	SeckillStock    int //每张秒杀券的库存
+	Blogs           int
+	BatchSize       int
+}
+
+type User struct {
+	ID    uint64
+	Phone string
+}
+
+type Result struct {
+	Users           []User
+	Shops           int
+	Vouchers        int
+	SeckillVouchers []uint64 //秒杀券id，压测时使用
+	Blogs           int
+	Follows         int
+}
+
+var (
+	phonePrefixes = []string{"130", "131", "132", "135", "136", "137", "138", "139", "150", "151", "152", "158", "159", "186", "187", "188"}
+	cities        = []string{"杭州", "上海", "北京", "深圳", "广州", "成都", "南京", "武汉"}
+	areas         = []string{"大关", "拱宸桥", "运河上街", "武林广场", "西湖", "湖滨", "钱江新城", "滨江", "下沙", "西溪"}
+	streets       = []string{"湖墅南路", "莫干山路", "丽水路", "上塘路", "延安路", "解放路", "文三路", "江南大道"}
+	shopWords     = map[string][]string{
+		"美食":    {"小馆", "私房菜", "火锅", "烧烤", "面馆", "茶餐厅", "饺子馆"},
+		"KTV":   {"KTV", "量贩KTV", "音乐会所"},
+		"丽人·美发": {"造型", "美发沙龙", "发型工作室"},
+		"健身运动":  {"健身房", "瑜伽馆", "游泳馆", "攀岩馆"},
+		"按摩·足疗": {"足道", "按摩馆", "养生会所"},
+		"美容SPA": {"SPA", "美容院", "皮肤管理中心"},
+		"亲子游乐":  {"亲子乐园", "儿童游乐场", "亲子餐厅"},
+		"酒吧":    {"酒吧", "精酿酒馆", "清吧"},
+		"轰趴馆":   {"轰趴馆", "派对别墅", "桌游吧"},
+		"美睫·美甲": {"美甲店", "美睫工作室", "美甲美睫"},
+	}
+	shopNamePrefixes = []string{"老街", "阿明", "小王", "好邻居", "星光", "一品", "开心", "云端", "运河", "湖畔", "金牌", "悦享"}
+	blogTitles       = []string{"无尽浪漫的夜晚丨在万花丛中摇晃着红酒杯🍷品战斧牛排🥩", "人均50吃到撑，这家店真的可以", "周末带娃去玩了一整天", "下班后的小确幸", "朋友推荐的宝藏店铺，果然没失望", "排队一小时，值不值？"}
+	blogSentences    = []string{"环境很干净，服务员也很热情。", "价格实惠，分量很足。", "停车有点不方便，建议地铁过来。", "招牌一定要点，真的好吃！", "周末人比较多，最好提前预约。", "整体体验不错，下次还会再来。", "性价比很高，推荐给大家。"}
+)
+
+// Run 按Options生成数据
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	g := &generator{opts: opts, rnd: rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)), now: time.Now()}
+	res := &Result{}
+	var err error
+	//1.用户和关注
+	if res.Users, err = g.users(ctx); err != nil {
+		return nil, fmt.Errorf("seed users: %w", err)
+	}
+	if res.Follows, err = g.follows(ctx, res.Users); err != nil {
+		return nil, fmt.Errorf("seed follows: %w", err)
+	}
+	//2.商户和优惠券
+	shops, err := g.shops(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("seed shops: %w", err)
+	}
+	res.Shops = len(shops)
+	if res.Vouchers, err = g.vouchers(ctx, shops); err != nil {
+		return nil, fmt.Errorf("seed vouchers: %w", err)
+	}
+	if res.SeckillVouchers, err = g.seckillVouchers(ctx, shops); err != nil {
+		return nil, fmt.Errorf("seed seckill vouchers: %w", err)
+	}
+	//3.博客
+	if res.Blogs, err = g.blogs(ctx, res.Users, shops); err != nil {
+		return nil, fmt.Errorf("seed blogs: %w", err)
+	}
+	return res, nil
+}
+
+type generator struct {
+	opts Options
+	rnd  *rand.Rand
+	now  time.Time
+}
+
+func (g *generator) pick(s []string) string {
+	return s[g.rnd.IntN(len(s))]
+}
+
+func (g *generator) users(ctx context.Context) ([]User, error) {
+	if g.opts.Users == 0 {
+		return nil, nil
+	}
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	phones := make([]string, 0, g.opts.Users)
+	seen := make(map[string]struct{}, g.opts.Users)
+	users := make([]*model.TbUser, 0, g.opts.Users)
+	for len(users) < g.opts.Users {
+		phone := g.pick(phonePrefixes) + fmt.Sprintf("%08d", g.rnd.IntN(1e8))
+		if _, ok := seen[phone]; ok {
+			continue
+		}
+		seen[phone] = struct{}{}
+		var nick strings.Builder
+		nick.WriteString("user_")
+		for range 10 {
+			nick.WriteByte(letters[g.rnd.IntN(len(letters))])
+		}
+		phones = append(phones, phone)
+		users = append(users, &model.TbUser{Phone: phone, NickName: nick.String()})
+	}
+	//已经存在的手机号跳过，然后按手机号查出id
+	u := query.TbUser
+	err := u.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(users, g.opts.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint64, len(phones))
+	for start := 0; start < len(phones); start += g.opts.BatchSize {
+		batch := phones[start:min(start+g.opts.BatchSize, len(phones))]
+		rows, err := u.WithContext(ctx).WriteDB().Select(u.ID, u.Phone).Where(u.Phone.In(batch...)).Find()
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			ids[row.Phone] = row.ID
+		}
+	}
+	res := make([]User, 0, len(phones))
+	infos := make([]*model.TbUserInfo, 0, len(phones))
+	for _, phone := range phones {
+		id, ok := ids[phone]
+		if !ok {
+			return nil, errors.New("user not found after insert: " + phone)
+		}
+		res = append(res, User{ID: id, Phone: phone})
+		infos = append(infos, &model.TbUserInfo{
+			UserID:   id,
+			City:     g.pick(cities),
+			Gender:   uint32(g.rnd.IntN(2)),
+			Birthday: time.Date(1970+g.rnd.IntN(36), time.Month(1+g.rnd.IntN(12)), 1+g.rnd.IntN(28), 0, 0, 0, 0, time.Local),
+		})
+	}
+	err = query.TbUserInfo.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(infos, g.opts.BatchSize)
+	return res, err
+}
+
+// 每个用户随机关注几个其他用户，然后按tb_follow重新统计粉丝数和关注数
+func (g *generator) follows(ctx context.Context, users []User) (int, error) {
+	n := min(g.opts.FollowsPerUser, len(users)-1)
+	if n <= 0 {
+		return 0, nil
+	}
+	follows := make([]*model.TbFollow, 0, len(users)*n)
+	for i, user := range users {
+		picked := map[int]struct{}{i: {}}
+		for len(picked) <= n {
+			j := g.rnd.IntN(len(users))
+			if _, ok := picked[j]; ok {
+				continue
+			}
+			picked[j] = struct{}{}
+			follows = append(follows, &model.TbFollow{UserID: user.ID, FollowUserID: users[j].ID})
+		}
+	}
+	err := query.TbFollow.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(follows, g.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	// 不能按这次生成的关注直接累加：重复执行seed时已经存在的关注会被跳过，原有的关注也要算进去
+	q := query.Use(db.DBEngine)
+	err = q.Transaction(func(tx *query.Query) error {
+		info, follow := tx.TbUserInfo, tx.TbFollow
+		for _, user := range users {
+			fans, err := follow.WithContext(ctx).Where(follow.FollowUserID.Eq(user.ID)).Count()
+			if err != nil {
+				return err
+			}
+			followee, err := follow.WithContext(ctx).Where(follow.UserID.Eq(user.ID)).Count()
+			if err != nil {
+				return err
+			}
+			_, err = info.WithContext(ctx).Where(info.UserID.Eq(user.ID)).
+				UpdateSimple(info.Fans.Value(uint32(fans)), info.Followee.Value(uint32(followee)))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(follows), err
+}
+
+func (g *generator) shops(ctx context.Context) ([]*model.TbShop, error) {
+	if g.opts.Shops == 0 {
+		return nil, nil
+	}
+	t := query.TbShopType
+	types, err := t.WithContext(ctx).Order(t.ID).Find()
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, errors.New("tb_shop_type is empty, run `xzdp migrate up` first")
+	}
+	shops := make([]*model.TbShop, 0, g.opts.Shops)
+	for i := 0; i < g.opts.Shops; i++ {
+		shopType := types[i%len(types)]
+		words := shopWords[shopType.Name]
+		if len(words) == 0 {
+			words = []string{shopType.Name}
+		}
+		area := g.pick(areas)
+		shops = append(shops, &model.TbShop{
+			Name:    g.pick(shopNamePrefixes) + g.pick(words) + "(" + area + "店)",
+			TypeID:  shopType.ID,
+			Area:    area,
+			Address: fmt.Sprintf("%s%d号", g.pick(streets), 1+g.rnd.IntN(999)),
+			//杭州市区附近
+			X:         120.05 + g.rnd.Float64()*0.25,
+			Y:         30.15 + g.rnd.Float64()*0.2,
+			AvgPrice:  uint64(20 + g.rnd.IntN(280)),
+			Sold:      uint32(g.rnd.IntN(10000)),
+			Comments:  uint32(g.rnd.IntN(5000)),
+			Score:     uint32(30 + g.rnd.IntN(21)),
+			OpenHours: fmt.Sprintf("%02d:00-%02d:00", 8+g.rnd.IntN(4), 20+g.rnd.IntN(4)),
+		})
+	}
+	err = query.TbShop.WithContext(ctx).CreateInBatches(shops, g.opts.BatchSize)
+	return shops, err
+}
+
+func (g *generator) voucher(shopId uint64, voucherType uint32) *model.TbVoucher {
+	actual := int64(50+g.rnd.IntN(10)*50) * 100
+	return &model.TbVoucher{
+		ShopID:      shopId,
+		Title:       fmt.Sprintf("%d元代金券", actual/100),
+		SubTitle:    "周一至周日均可使用",
+		Rules:       "全场通用\\n无需预约\\n可无限叠加\\n不兑现、不找零\\n仅限堂食",
+		PayValue:    uint64(actual * int64(70+g.rnd.IntN(25)) / 100),
+		ActualValue: actual,
+		Type:        voucherType,
+		Status:      1,
+	}
+}
+
+func (g *generator) vouchers(ctx context.Context, shops []*model.TbShop) (int, error) {
+	vouchers := make([]*model.TbVoucher, 0, len(shops)*g.opts.VouchersPerShop)
+	for _, shop := range shops {
+		for range g.opts.VouchersPerShop {
+			vouchers = append(vouchers, g.voucher(shop.ID, 0))
+		}
+	}
+	if len(vouchers) == 0 {
+		return 0, nil
+	}
+	return len(vouchers), query.TbVoucher.WithContext(ctx).CreateInBatches(vouchers, g.opts.BatchSize)
+}
+
+// 秒杀券已经开始，一周后结束
+func (g *generator) seckillVouchers(ctx context.Context, shops []*model.TbShop) ([]uint64, error) {
+	if g.opts.SeckillVouchers == 0 || len(shops) == 0 {
+		return nil, nil
+	}
+	vouchers := make([]*model.TbVoucher, 0, g.opts.SeckillVouchers)
+	for range g.opts.SeckillVouchers {
+		vouchers = append(vouchers, g.voucher(shops[g.rnd.IntN(len(shops))].ID, 1))
+	}
+	ids := make([]uint64, 0, len(vouchers))
+	q := query.Use(db.DBEngine)
+	err := q.Transaction(func(tx *query.Query) error {
+		if err := tx.TbVoucher.WithContext(ctx).CreateInBatches(vouchers, g.opts.BatchSize); err != nil {
+			return err
+		}
+		seckills := make([]*model.TbSeckillVoucher, 0, len(vouchers))
+		for _, v := range vouchers {
+			ids = append(ids, v.ID)
+			seckills = append(seckills, &model.TbSeckillVoucher{
+				VoucherID: v.ID,
+				Stock:     int32(g.opts.SeckillStock),
+				BeginTime: g.now.Add(-time.Hour),
+				EndTime:   g.now.Add(7 * 24 * time.Hour),
+			})
+		}
+		return tx.TbSeckillVoucher.WithContext(ctx).CreateInBatches(seckills, g.opts.BatchSize)
+	})
+	return ids, err
+}
+
+// 博客发布时间分布在最近30天
+func (g *generator) blogs(ctx context.Context, users []User, shops []*model.TbShop) (int, error) {
+	if g.opts.Blogs == 0 || len(users) == 0 || len(shops) == 0 {
+		return 0, nil
+	}
+	blogs := make([]*model.TbBlog, 0, g.opts.Blogs)
+	for range g.opts.Blogs {
+		sentences := make([]string, 2+g.rnd.IntN(4))
+		for i := range sentences {
+			sentences[i] = g.pick(blogSentences)
+		}
+		created := g.now.Add(-time.Duration(g.rnd.Int64N(int64(30 * 24 * time.Hour))))
+		blogs = append(blogs, &model.TbBlog{
+			ShopID:     int64(shops[g.rnd.IntN(len(shops))].ID),
+			UserID:     users[g.rnd.IntN(len(users))].ID,
+			Title:      g.pick(blogTitles),
+			Content:    strings.Join(sentences, ""),
+			Liked:      uint32(g.rnd.IntN(500)),
+			Comments:   uint32(g.rnd.IntN(100)),
+			CreateTime: created,
+			UpdateTime: created,
+		})
+	}
+	return len(blogs), query.TbBlog.WithContext(ctx).CreateInBatches(blogs, g.opts.BatchSize)
+}
@@ -0,0 +1,80 @@
+package Account
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"xzdp/middleware"
+	"xzdp/pkg/response"
+
+	"github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// tb_account_deletion.status
+const (
+	deletionPending uint32 = 0
+	deletionDone    uint32 = 1
+)
+
+const deletedNickName = "已注销用户"
+
+// GET /api/user/export?format=json|zip 导出个人数据
+func ExportData(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		response.Error(c, response.ErrValidation, "format只能是json或zip")
+		return
+	}
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	data, err := loadExportData(c, uint64(userId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, response.ErrNotFound, "用户不存在")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "导出用户数据失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	fileName := "xzdp-export-" + strconv.FormatInt(userId, 10) + "-" + data.ExportTime.Format("20060102150405")
+	var buf bytes.Buffer
+	contentType := "application/json"
+	if format == "zip" {
+		contentType = "application/zip"
+		err = writeExportZip(&buf, data)
+	} else {
+		var b []byte
+		b, err = sonic.ConfigStd.MarshalIndent(data, "", "  ")
+		buf.Write(b)
+	}
+	if err != nil {
+		slog.ErrorContext(c, "打包用户数据失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrEncodingFailed)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+"."+format+`"`)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// POST /api/user/deactivate 申请注销账号，冷静期内重新登录即可撤销
+func RequestDeletion(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	deletion, err := requestDeletionToDB(c, uint64(userId))
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "申请注销失败"))
+		return
+	}
+	//退出所有设备，冷静期内重新登录会自动撤销注销申请
+	if err = middleware.RevokeAllSessions(c, userId); err != nil {
+		slog.ErrorContext(c, "申请注销后退出所有设备失败", "userId", userId, "err", err)
+	}
+	slog.InfoContext(c, "申请注销账号", "userId", userId, "purgeTime", deletion.PurgeTime)
+	response.Success(c, deletionResponse{
+		PurgeTime: deletion.PurgeTime,
+		Message:   "已申请注销，冷静期结束前重新登录即可撤销",
+	})
+}
@@ -0,0 +1,58 @@
+package Account
+
+import (
+	"time"
+	"xzdp/dal/model"
+)
+
+// 导出的用户数据，不包含密码
+type exportProfile struct {
+	ID         uint64    `json:"id"`
+	Phone      string    `json:"phone"`
+	NickName   string    `json:"nickName"`
+	Icon       string    `json:"icon"`
+	City       string    `json:"city"`
+	Introduce  string    `json:"introduce"`
+	Gender     uint32    `json:"gender"`
+	Birthday   string    `json:"birthday,omitempty"`
+	Credits    uint32    `json:"credits"`
+	Level      uint32    `json:"level"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+type exportData struct {
+	ExportTime time.Time               `json:"exportTime"`
+	Profile    exportProfile           `json:"profile"`
+	Orders     []*model.TbVoucherOrder `json:"orders"`
+	Blogs      []*model.TbBlog         `json:"blogs"`
+	Comments   []*model.TbBlogComment  `json:"comments"`
+	Follows    []*model.TbFollow       `json:"follows"`
+	Signs      []*model.TbSign         `json:"signs"`
+	CreditLogs []*model.TbCreditLog    `json:"creditLogs"`
+}
+
+type deletionResponse struct {
+	PurgeTime time.Time `json:"purgeTime"` // 冷静期结束、执行注销的时间
+	Message   string    `json:"message"`
+}
+
+func newExportProfile(user *model.TbUser, info *model.TbUserInfo) exportProfile {
+	p := exportProfile{
+		ID:         user.ID,
+		Phone:      user.Phone,
+		NickName:   user.NickName,
+		Icon:       user.Icon,
+		CreateTime: user.CreateTime,
+	}
+	if info != nil {
+		p.City = info.City
+		p.Introduce = info.Introduce
+		p.Gender = info.Gender
+		p.Credits = info.Credits
+		p.Level = info.Level
+		if !info.Birthday.IsZero() {
+			p.Birthday = info.Birthday.Format(time.DateOnly)
+		}
+	}
+	return p
+}
@@ -0,0 +1,112 @@
+package Account
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"time"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+
+	"github.com/bytedance/sonic"
+	"gorm.io/gorm"
+)
+
+// 查询用户的所有数据
+func loadExportData(ctx context.Context, userId uint64) (*exportData, error) {
+	q := query.Use(db.DBEngine).WithContext(ctx)
+	user, err := q.TbUser.Where(query.TbUser.ID.Eq(userId)).First()
+	if err != nil {
+		return nil, err
+	}
+	info, err := q.TbUserInfo.Where(query.TbUserInfo.UserID.Eq(userId)).First()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	data := &exportData{ExportTime: time.Now(), Profile: newExportProfile(user, info)}
+	if data.Orders, err = q.TbVoucherOrder.Where(query.TbVoucherOrder.UserID.Eq(userId)).Find(); err != nil {
+		return nil, err
+	}
+	if data.Blogs, err = q.TbBlog.Where(query.TbBlog.UserID.Eq(userId)).Find(); err != nil {
+		return nil, err
+	}
+	if data.Comments, err = q.TbBlogComment.Where(query.TbBlogComment.UserID.Eq(userId)).Find(); err != nil {
+		return nil, err
+	}
+	if data.Follows, err = q.TbFollow.Where(query.TbFollow.UserID.Eq(userId)).Find(); err != nil {
+		return nil, err
+	}
+	if data.Signs, err = q.TbSign.Where(query.TbSign.UserID.Eq(userId)).Find(); err != nil {
+		return nil, err
+	}
+	if data.CreditLogs, err = q.TbCreditLog.Where(query.TbCreditLog.UserID.Eq(userId)).Find(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// 打包成zip，每类数据一个json文件
+func writeExportZip(w io.Writer, data *exportData) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"profile.json", data.Profile},
+		{"orders.json", data.Orders},
+		{"blogs.json", data.Blogs},
+		{"comments.json", data.Comments},
+		{"follows.json", data.Follows},
+		{"signs.json", data.Signs},
+		{"credit_logs.json", data.CreditLogs},
+	}
+	for _, f := range files {
+		b, err := sonic.ConfigStd.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: data.ExportTime})
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(b); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// 申请注销，已经申请过时返回之前的申请
+func requestDeletionToDB(ctx context.Context, userId uint64) (*model.TbAccountDeletion, error) {
+	d := query.TbAccountDeletion
+	//重复申请时要读到刚写入的申请，读主库
+	deletion, err := d.WithContext(ctx).WriteDB().Where(d.UserID.Eq(userId), d.Status.Eq(deletionPending)).First()
+	if err == nil {
+		return deletion, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	deletion = &model.TbAccountDeletion{
+		UserID:    userId,
+		Status:    deletionPending,
+		PurgeTime: time.Now().Add(config.Current().Account.GracePeriod),
+	}
+	if err = d.WithContext(ctx).Create(deletion); err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+// CancelDeletion 撤销冷静期中的注销申请，返回是否有申请被撤销
+func CancelDeletion(ctx context.Context, userId uint64) (bool, error) {
+	d := query.TbAccountDeletion
+	info, err := d.WithContext(ctx).Where(d.UserID.Eq(userId), d.Status.Eq(deletionPending)).Delete()
+	if err != nil {
+		return false, err
+	}
+	return info.RowsAffected > 0, nil
+}
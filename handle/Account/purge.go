@@ -0,0 +1,160 @@
+package Account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"xzdp/config"
+	"xzdp/dal/query"
+	"xzdp/db"
+	"xzdp/handle/Sign"
+	"xzdp/handle/User/UserCache"
+	"xzdp/middleware"
+)
+
+// 定时执行冷静期已经结束的注销申请：
+// 1. tb_user 中的手机号替换为不可能登录的占位号码，清空昵称、头像和密码，用户id保留，订单仍然关联这个id用于对账
+// 2. 删除个人资料、关注关系、签到记录和角色
+// 3. 退出所有设备，清理缓存和Redis中的签到记录
+
+// StartPurger 启动注销协程，ctx取消时退出，退出后调用wg.Done
+func StartPurger(ctx context.Context, wg *sync.WaitGroup) {
+	interval := config.Current().Account.PurgeInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := purgeDueAccounts(ctx); err != nil {
+					slog.ErrorContext(ctx, "执行账号注销失败", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+func purgeDueAccounts(ctx context.Context) error {
+	d := query.TbAccountDeletion
+	due, err := d.WithContext(ctx).Where(d.Status.Eq(deletionPending), d.PurgeTime.Lte(time.Now())).Find()
+	if err != nil {
+		return err
+	}
+	for _, deletion := range due {
+		err = purgeAccount(ctx, deletion.UserID)
+		if errors.Is(err, errDeletionCanceled) {
+			slog.InfoContext(ctx, "注销申请已撤销，跳过", "userId", deletion.UserID)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "注销账号失败", "userId", deletion.UserID, "err", err)
+			continue
+		}
+		slog.InfoContext(ctx, "账号已注销", "userId", deletion.UserID)
+	}
+	return nil
+}
+
+// 手机号只能是11位，以0开头的号码不可能是真实手机号，也不会和其他注销的账号重复
+func anonymizedPhone(userId uint64) string {
+	return fmt.Sprintf("0%010d", userId%1e10)
+}
+
+// 查询到期的申请之后用户又登录撤销了申请
+var errDeletionCanceled = errors.New("account deletion canceled")
+
+func purgeAccount(ctx context.Context, userId uint64) error {
+	q := query.Use(db.DBEngine)
+	err := q.Transaction(func(tx *query.Query) error {
+		//1.先标记为已注销，申请已经被撤销时回滚，不能匿名化重新登录的用户
+		d := tx.TbAccountDeletion
+		info, err := d.WithContext(ctx).Where(d.UserID.Eq(userId), d.Status.Eq(deletionPending)).
+			UpdateSimple(d.Status.Value(deletionDone))
+		if err != nil {
+			return err
+		}
+		if info.RowsAffected == 0 {
+			return errDeletionCanceled
+		}
+		//2.匿名化用户，Updates会跳过零值，所以用map
+		u := tx.TbUser
+		_, err = u.WithContext(ctx).Where(u.ID.Eq(userId)).Updates(map[string]any{
+			u.Phone.ColumnName().String():    anonymizedPhone(userId),
+			u.NickName.ColumnName().String(): deletedNickName,
+			u.Icon.ColumnName().String():     "",
+			u.Password.ColumnName().String(): "",
+		})
+		if err != nil {
+			return err
+		}
+		//3.删除个人数据，订单保留
+		if _, err = tx.TbUserInfo.WithContext(ctx).Where(tx.TbUserInfo.UserID.Eq(userId)).Delete(); err != nil {
+			return err
+		}
+		if err = deleteFollows(ctx, tx, userId); err != nil {
+			return err
+		}
+		if _, err = tx.TbSign.WithContext(ctx).Where(tx.TbSign.UserID.Eq(userId)).Delete(); err != nil {
+			return err
+		}
+		if _, err = tx.TbUserRole.WithContext(ctx).Where(tx.TbUserRole.UserID.Eq(userId)).Delete(); err != nil {
+			return err
+		}
+		//解除第三方账号的绑定，否则用第三方账号还能登录到匿名化的账号
+		_, err = tx.TbUserIdentity.WithContext(ctx).Where(tx.TbUserIdentity.UserID.Eq(userId)).Delete()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	//4.清理Redis，失败时只记录日志，令牌和缓存过期后也会失效
+	id := int64(userId)
+	if err = middleware.RevokeAllSessions(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "注销账号时退出所有设备失败", "userId", userId, "err", err)
+	}
+	if err = middleware.InvalidateUserRoles(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "注销账号时清理角色缓存失败", "userId", userId, "err", err)
+	}
+	if err = Sign.DeleteUserSigns(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "注销账号时清理签到记录失败", "userId", userId, "err", err)
+	}
+	UserCache.DeleteInfo(ctx, userId)
+	return nil
+}
+
+// 删除用户的关注关系，同时减少被关注的人的粉丝数和粉丝的关注数
+func deleteFollows(ctx context.Context, tx *query.Query, userId uint64) error {
+	f := tx.TbFollow
+	var followees, fans []uint64
+	if err := f.WithContext(ctx).Where(f.UserID.Eq(userId)).Pluck(f.FollowUserID, &followees); err != nil {
+		return err
+	}
+	if err := f.WithContext(ctx).Where(f.FollowUserID.Eq(userId)).Pluck(f.UserID, &fans); err != nil {
+		return err
+	}
+	info := tx.TbUserInfo
+	if len(followees) > 0 {
+		_, err := info.WithContext(ctx).Where(info.UserID.In(followees...), info.Fans.Gt(0)).UpdateSimple(info.Fans.Sub(1))
+		if err != nil {
+			return err
+		}
+	}
+	if len(fans) > 0 {
+		_, err := info.WithContext(ctx).Where(info.UserID.In(fans...), info.Followee.Gt(0)).UpdateSimple(info.Followee.Sub(1))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := f.WithContext(ctx).Where(f.UserID.Eq(userId)).Or(f.FollowUserID.Eq(userId)).Delete()
+	return err
+}
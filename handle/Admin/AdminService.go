@@ -0,0 +1,136 @@
+package Admin
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"xzdp/config"
+	"xzdp/middleware"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GET /api/admin/roles 所有角色和它们的权限
+func ListRoles(c *gin.Context) {
+	roles, err := getRolesFromDB(c)
+	if err != nil {
+		slog.ErrorContext(c, "查询角色失败", "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	response.Success(c, roles)
+}
+
+// GET /api/admin/config 当前生效的配置，密码和密钥已隐藏
+func GetConfig(c *gin.Context) {
+	response.Success(c, config.Current().Redacted())
+}
+
+// GET /api/admin/users/:userId/roles
+func GetUserRoles(c *gin.Context) {
+	userId, ok := parseUserId(c)
+	if !ok {
+		return
+	}
+	codes, err := getUserRoleCodesFromDB(c, userId)
+	if err != nil {
+		slog.ErrorContext(c, "查询用户角色失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	response.Success(c, append([]string{middleware.RoleUser}, codes...))
+}
+
+// POST /api/admin/users/:userId/roles 授予角色，立即生效
+func GrantRole(c *gin.Context) {
+	userId, ok := parseUserId(c)
+	if !ok {
+		return
+	}
+	var req grantRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	//1.校验用户和角色
+	exists, err := userExists(c, userId)
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	if !exists {
+		response.Error(c, response.ErrNotFound, "用户不存在")
+		return
+	}
+	role, ok := findRole(c, req.Role)
+	if !ok {
+		return
+	}
+	//2.写入数据库，让用户的令牌重新查询角色
+	if err = grantRoleToDB(c, userId, role); err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "授权失败"))
+		return
+	}
+	if err = middleware.InvalidateUserRoles(c, int64(userId)); err != nil {
+		slog.ErrorContext(c, "刷新用户角色失败", "userId", userId, "err", err)
+	}
+	slog.InfoContext(c, "授予角色", "operator", c.GetInt64(middleware.CtxKeyUserId), "userId", userId, "role", req.Role)
+	response.Success(c, gin.H{"message": "授权成功"})
+}
+
+// DELETE /api/admin/users/:userId/roles/:role 撤销角色，立即生效
+func RevokeRole(c *gin.Context) {
+	userId, ok := parseUserId(c)
+	if !ok {
+		return
+	}
+	code := c.Param("role")
+	//1.不能撤销自己的管理员角色，避免没有管理员
+	if code == middleware.RoleAdmin && int64(userId) == c.GetInt64(middleware.CtxKeyUserId) {
+		response.Error(c, response.ErrValidation, "不能撤销自己的管理员角色")
+		return
+	}
+	role, ok := findRole(c, code)
+	if !ok {
+		return
+	}
+	//2.删除记录，让用户的令牌重新查询角色
+	if err := revokeRoleFromDB(c, userId, role); err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "撤销失败"))
+		return
+	}
+	if err := middleware.InvalidateUserRoles(c, int64(userId)); err != nil {
+		slog.ErrorContext(c, "刷新用户角色失败", "userId", userId, "err", err)
+	}
+	slog.InfoContext(c, "撤销角色", "operator", c.GetInt64(middleware.CtxKeyUserId), "userId", userId, "role", code)
+	response.Success(c, gin.H{"message": "撤销成功"})
+}
+
+func parseUserId(c *gin.Context) (uint64, bool) {
+	userId, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "无效的用户id")
+		return 0, false
+	}
+	return userId, true
+}
+
+// 查找可以授予/撤销的角色，user角色所有人默认拥有，不能修改
+func findRole(c *gin.Context, code string) (uint64, bool) {
+	if code == middleware.RoleUser {
+		response.Error(c, response.ErrValidation, "所有用户默认拥有user角色")
+		return 0, false
+	}
+	role, err := getRoleByCodeFromDB(c, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, response.ErrNotFound, "角色不存在")
+		return 0, false
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return 0, false
+	}
+	return role.ID, true
+}
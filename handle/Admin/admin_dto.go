@@ -0,0 +1,12 @@
+package Admin
+
+// 授予角色请求结构体
+type grantRoleReq struct {
+	Role string `json:"role" binding:"required"` // 角色编码，例如merchant
+}
+
+type roleResponse struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,69 @@
+package Admin
+
+import (
+	"context"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+
+	"gorm.io/gorm/clause"
+)
+
+func getRoleByCodeFromDB(ctx context.Context, code string) (*model.TbRole, error) {
+	r := query.TbRole
+	return r.WithContext(ctx).Where(r.Code.Eq(code)).First()
+}
+
+// 所有角色和它们的权限
+func getRolesFromDB(ctx context.Context) ([]roleResponse, error) {
+	r := query.TbRole
+	roles, err := r.WithContext(ctx).Order(r.ID).Find()
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		RoleID uint64
+		Perm   string
+	}
+	rp, p := query.TbRolePermission, query.TbPermission
+	err = rp.WithContext(ctx).Select(rp.RoleID, p.Code.As("perm")).
+		Join(p, p.ID.EqCol(rp.PermissionID)).Scan(&rows)
+	if err != nil {
+		return nil, err
+	}
+	perms := make(map[uint64][]string)
+	for _, row := range rows {
+		perms[row.RoleID] = append(perms[row.RoleID], row.Perm)
+	}
+	res := make([]roleResponse, 0, len(roles))
+	for _, role := range roles {
+		res = append(res, roleResponse{Code: role.Code, Name: role.Name, Permissions: perms[role.ID]})
+	}
+	return res, nil
+}
+
+// 用户在tb_user_role中的角色，不包含默认的user角色
+func getUserRoleCodesFromDB(ctx context.Context, userId uint64) ([]string, error) {
+	r, ur := query.TbRole, query.TbUserRole
+	var codes []string
+	err := r.WithContext(ctx).Join(ur, ur.RoleID.EqCol(r.ID)).
+		Where(ur.UserID.Eq(userId)).Order(r.ID).Pluck(r.Code, &codes)
+	return codes, err
+}
+
+// 重复授权时忽略
+func grantRoleToDB(ctx context.Context, userId uint64, roleId uint64) error {
+	return query.TbUserRole.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.TbUserRole{UserID: userId, RoleID: roleId})
+}
+
+func revokeRoleFromDB(ctx context.Context, userId uint64, roleId uint64) error {
+	ur := query.TbUserRole
+	_, err := ur.WithContext(ctx).Where(ur.UserID.Eq(userId), ur.RoleID.Eq(roleId)).Delete()
+	return err
+}
+
+func userExists(ctx context.Context, userId uint64) (bool, error) {
+	u := query.TbUser
+	count, err := u.WithContext(ctx).Where(u.ID.Eq(userId)).Count()
+	return count > 0, err
+}
@@ -0,0 +1,111 @@
+package Admin
+
+import (
+	"context"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+	"xzdp/middleware"
+
+	"gorm.io/gorm/clause"
+)
+
+// 默认的角色和权限，启动时写入数据库（已经存在的不会修改），admin拥有所有权限，不需要配置
+var defaultPermissions = map[string]string{
+	middleware.PermShopWrite:    "新增、修改、删除商户",
+	middleware.PermVoucherWrite: "新增优惠券",
+	middleware.PermBlogModerate: "管理所有人的博客",
+	middleware.PermUserRole:     "授权和撤销用户角色",
+	middleware.PermConfigRead:   "查看当前生效的配置",
+}
+
+var defaultRoles = []struct {
+	Code  string
+	Name  string
+	Perms []string
+}{
+	{middleware.RoleUser, "普通用户", nil},
+	{middleware.RoleMerchant, "商家", []string{middleware.PermShopWrite, middleware.PermVoucherWrite}},
+	{middleware.RoleModerator, "版主", []string{middleware.PermBlogModerate}},
+	{middleware.RoleAdmin, "管理员", nil},
+}
+
+// InitRoles 写入默认的角色和权限，并给配置中的用户授予管理员角色
+func InitRoles(ctx context.Context) error {
+	q := query.Use(db.DBEngine)
+	err := q.Transaction(func(tx *query.Query) error {
+		//1.权限
+		perms := make([]*model.TbPermission, 0, len(defaultPermissions))
+		for code, name := range defaultPermissions {
+			perms = append(perms, &model.TbPermission{Code: code, Name: name})
+		}
+		err := tx.TbPermission.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(perms...)
+		if err != nil {
+			return err
+		}
+		//2.角色
+		roles := make([]*model.TbRole, 0, len(defaultRoles))
+		for _, r := range defaultRoles {
+			roles = append(roles, &model.TbRole{Code: r.Code, Name: r.Name})
+		}
+		err = tx.TbRole.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(roles...)
+		if err != nil {
+			return err
+		}
+		//3.角色和权限的关联，插入时数据库中可能已经有记录，所以重新查询id
+		roleIds, err := getRoleIds(ctx, tx)
+		if err != nil {
+			return err
+		}
+		var permRows []*model.TbPermission
+		if permRows, err = tx.TbPermission.WithContext(ctx).Find(); err != nil {
+			return err
+		}
+		permIds := make(map[string]uint64, len(permRows))
+		for _, p := range permRows {
+			permIds[p.Code] = p.ID
+		}
+		var bindings []*model.TbRolePermission
+		for _, r := range defaultRoles {
+			for _, p := range r.Perms {
+				bindings = append(bindings, &model.TbRolePermission{RoleID: roleIds[r.Code], PermissionID: permIds[p]})
+			}
+		}
+		err = tx.TbRolePermission.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(bindings...)
+		if err != nil {
+			return err
+		}
+		//4.初始管理员
+		admins := make([]*model.TbUserRole, 0, len(config.RBACOption.Admins))
+		for _, userId := range config.RBACOption.Admins {
+			admins = append(admins, &model.TbUserRole{UserID: userId, RoleID: roleIds[middleware.RoleAdmin]})
+		}
+		if len(admins) == 0 {
+			return nil
+		}
+		return tx.TbUserRole.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(admins...)
+	})
+	if err != nil {
+		return err
+	}
+	middleware.ReloadRolePermissions()
+	for _, userId := range config.RBACOption.Admins {
+		if err = middleware.InvalidateUserRoles(ctx, int64(userId)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getRoleIds(ctx context.Context, tx *query.Query) (map[string]uint64, error) {
+	roles, err := tx.TbRole.WithContext(ctx).Find()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint64, len(roles))
+	for _, r := range roles {
+		ids[r.Code] = r.ID
+	}
+	return ids, nil
+}
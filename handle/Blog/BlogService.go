@@ -0,0 +1,320 @@
+package Blog
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"xzdp/config"
+	"xzdp/handle/Credit"
+	"xzdp/middleware"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	hotRankKey         = "blog:hot:rank"
+	hotRankRebuiltKey  = "blog:hot:rebuilt" //存在表示排行榜已经从数据库重建过
+	blogLikedKeyPrefix = "blog:liked:"
+	blogCacheKeyPrefix = "cache:blog:"
+	userBlogPageSize   = 10
+)
+
+// GET /api/blog/hot?current=1
+func GetHotBlog(c *gin.Context) {
+	//1.解析页码，默认第一页
+	current, err := strconv.ParseInt(c.DefaultQuery("current", "1"), 10, 64)
+	if err != nil || current < 1 {
+		response.Error(c, response.ErrValidation, "无效的页码")
+		return
+	}
+	pageSize := config.Current().HotBlog.PageSize
+	if pageSize <= 0 {
+		slog.ErrorContext(c, "热门博客每页数量配置错误", "pageSize", pageSize)
+		response.Error(c, response.ErrUnknown, "查询热门博客失败")
+		return
+	}
+	//2.从排行榜拿到这一页的博客id
+	ids, err := getHotBlogIds(c, current, pageSize)
+	if err != nil {
+		slog.ErrorContext(c, "查询热门博客排行榜失败", "err", err)
+		response.Error(c, response.ErrDatabase, "查询热门博客失败")
+		return
+	}
+	//3.按排行榜的顺序查询博客详情
+	blogs, err := getBlogsByIdsFromDB(c, ids)
+	if err != nil {
+		response.Error(c, response.ErrDatabase, "查询db失败")
+		return
+	}
+	res := make([]*blogResponse, 0, len(blogs))
+	for _, b := range blogs {
+		res = append(res, blogModelToResponse(b))
+	}
+	//4.补充作者信息和点赞状态
+	if err = fillBlogAuthor(c, res); err != nil {
+		slog.ErrorContext(c, "查询博客作者失败", "err", err)
+	}
+	fillBlogIsLike(c, res, c.GetInt64(middleware.CtxKeyUserId))
+	response.Success(c, res)
+}
+
+// PUT /api/blog/like/:id
+func LikeBlog(c *gin.Context) {
+	blogId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "无效的博客id")
+		return
+	}
+	blog, err := getBlogByIdFromDB(c, blogId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, response.ErrNotFound, "博客不存在")
+		return
+	}
+	if err != nil {
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	//1.点赞或者取消点赞
+	isLike, err := toggleBlogLike(c, blogId, userId)
+	if err != nil {
+		slog.ErrorContext(c, "点赞失败", "blogId", blogId, "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase, "点赞失败")
+		return
+	}
+	//2.点赞数变化了，更新热度，并给作者发放积分
+	refreshBlogStats(c, blogId)
+	if isLike {
+		Credit.AwardLiked(c, blog.UserID, blogId, userId)
+	}
+	response.Success(c, gin.H{"isLike": isLike})
+}
+
+// POST /api/blog
+func PublishBlog(c *gin.Context) {
+	var req publishBlogReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	userId := uint64(c.GetInt64(middleware.CtxKeyUserId))
+	//1.保存博客
+	blog := req.ToModel(userId)
+	if err = createBlogToDB(c, blog); err != nil {
+		slog.ErrorContext(c, "发布博客失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase, "发布失败")
+		return
+	}
+	//2.新博客直接进入排行榜，发布时间越晚基础热度越高
+	refreshBlogStats(c, blog.ID)
+	//3.发放积分
+	Credit.AwardBlog(c, userId, blog.ID)
+	response.Success(c, gin.H{"id": blog.ID})
+}
+
+// POST /api/blog/comments
+func AddComment(c *gin.Context) {
+	var req addCommentReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	//1.博客必须存在
+	_, err = getBlogByIdFromDB(c, req.BlogID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, response.ErrNotFound, "博客不存在")
+		return
+	}
+	if err != nil {
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	//2.保存评论并增加评论数
+	comment := req.ToModel(uint64(c.GetInt64(middleware.CtxKeyUserId)))
+	if err = addCommentToDB(c, comment); err != nil {
+		slog.ErrorContext(c, "保存评论失败", "blogId", req.BlogID, "err", err)
+		response.Error(c, response.ErrDatabase, "评论失败")
+		return
+	}
+	//3.评论数变化了，更新热度
+	refreshBlogStats(c, req.BlogID)
+	response.Success(c, gin.H{"id": comment.ID})
+}
+
+// 点赞数、评论数变化后删除详情缓存并重新计算热度，失败只记录日志，不影响主流程
+func refreshBlogStats(c *gin.Context, blogId uint64) {
+	if err := deleteBlogDetailFromCache(c, blogId); err != nil {
+		slog.ErrorContext(c, "删除博客缓存失败", "blogId", blogId, "err", err)
+	}
+	blog, err := getBlogByIdFromDB(c, blogId)
+	if err != nil {
+		slog.ErrorContext(c, "更新博客热度时查询博客失败", "blogId", blogId, "err", err)
+		return
+	}
+	if err = updateHotRank(c, blog); err != nil {
+		slog.ErrorContext(c, "更新博客热度失败", "blogId", blogId, "err", err)
+	}
+}
+
+// GET /api/blog/:id
+func GetBlogById(c *gin.Context) {
+	blogId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "无效的博客id")
+		return
+	}
+	//1.从缓存中查找
+	blog, err := getBlogDetailFromCache(c, blogId)
+	//2.缓存未命中，从数据库查询博客、作者和商户
+	if blog == nil || err != nil {
+		dbBlog, err := getBlogByIdFromDB(c, blogId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.ErrNotFound, "博客不存在")
+			return
+		}
+		if err != nil {
+			response.Error(c, response.ErrDatabase)
+			return
+		}
+		blog = &blogDetailResponse{blogResponse: blogModelToResponse(dbBlog)}
+		if err = fillBlogAuthor(c, []*blogResponse{blog.blogResponse}); err != nil {
+			slog.ErrorContext(c, "查询博客作者失败", "blogId", blogId, "err", err)
+		}
+		if dbBlog.ShopID > 0 {
+			shop, err := getShopForBlog(c, dbBlog.ShopID)
+			if err == nil {
+				blog.Shop = shopModelToBlogShop(shop)
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				slog.ErrorContext(c, "查询博客商户失败", "shopId", dbBlog.ShopID, "err", err)
+			}
+		}
+		//3.写回缓存
+		if err = setBlogDetailToCache(c, blog); err != nil {
+			slog.ErrorContext(c, "设置博客缓存失败", "blogId", blogId, "err", err)
+		}
+	}
+	//4.点赞状态因人而异，不走缓存
+	fillBlogIsLike(c, []*blogResponse{blog.blogResponse}, c.GetInt64(middleware.CtxKeyUserId))
+	response.Success(c, blog)
+}
+
+// GET /api/blog/of/me?lastId=
+func GetMyBlogs(c *gin.Context) {
+	queryUserBlogs(c, uint64(c.GetInt64(middleware.CtxKeyUserId)))
+}
+
+// GET /api/blog/of/user?id=&lastId=
+func GetBlogsOfUser(c *gin.Context) {
+	userId, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil || userId == 0 {
+		response.Error(c, response.ErrValidation, "无效的用户id")
+		return
+	}
+	queryUserBlogs(c, userId)
+}
+
+// 返回的是博客数组，下一页把最后一条博客的id作为lastId传回来即可
+func queryUserBlogs(c *gin.Context, userId uint64) {
+	lastId, err := strconv.ParseUint(c.DefaultQuery("lastId", "0"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "无效的lastId")
+		return
+	}
+	blogs, err := getBlogsByUserFromDB(c, userId, lastId)
+	if err != nil {
+		slog.ErrorContext(c, "查询用户博客失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	res := make([]*blogResponse, 0, len(blogs))
+	for _, b := range blogs {
+		res = append(res, blogModelToResponse(b))
+	}
+	if err = fillBlogAuthor(c, res); err != nil {
+		slog.ErrorContext(c, "查询博客作者失败", "userId", userId, "err", err)
+	}
+	fillBlogIsLike(c, res, c.GetInt64(middleware.CtxKeyUserId))
+	response.Success(c, res)
+}
+
+// PUT /api/blog
+func UpdateBlog(c *gin.Context) {
+	var req updateBlogReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	//1.只有作者本人和版主可以修改
+	if !checkBlogAuthor(c, req.ID) {
+		return
+	}
+	//2.更新数据库再删除缓存
+	if err = updateBlogToDB(c, req.ID, req.ToModel()); err != nil {
+		slog.ErrorContext(c, "修改博客失败", "blogId", req.ID, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	if err = deleteBlogDetailFromCache(c, req.ID); err != nil {
+		slog.ErrorContext(c, "删除博客缓存失败", "blogId", req.ID, "err", err)
+	}
+	response.Success(c, nil)
+}
+
+// DELETE /api/blog/:id
+func DeleteBlog(c *gin.Context) {
+	blogId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "无效的博客id")
+		return
+	}
+	//1.只有作者本人和版主可以删除
+	if !checkBlogAuthor(c, blogId) {
+		return
+	}
+	//2.删除博客和它的评论
+	if err = deleteBlogFromDB(c, blogId); err != nil {
+		slog.ErrorContext(c, "删除博客失败", "blogId", blogId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	//3.清理缓存、点赞列表和排行榜
+	if err = deleteBlogFromRedis(c, blogId); err != nil {
+		slog.ErrorContext(c, "清理博客Redis数据失败", "blogId", blogId, "err", err)
+	}
+	if err = Credit.ClearLikedAwards(c, blogId); err != nil {
+		slog.ErrorContext(c, "清理点赞奖励记录失败", "blogId", blogId, "err", err)
+	}
+	response.Success(c, nil)
+}
+
+// 检查当前用户是否是博客作者或者版主，不是则直接写入错误响应并返回false
+func checkBlogAuthor(c *gin.Context, blogId uint64) bool {
+	blog, err := getBlogByIdFromDB(c, blogId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, response.ErrNotFound, "博客不存在")
+		return false
+	}
+	if err != nil {
+		response.Error(c, response.ErrDatabase)
+		return false
+	}
+	if blog.UserID == uint64(c.GetInt64(middleware.CtxKeyUserId)) {
+		return true
+	}
+	//版主可以管理所有人的博客
+	ok, err := middleware.HasPermission(c, middleware.PermBlogModerate)
+	if err != nil {
+		slog.ErrorContext(c, "检查权限失败", "err", err)
+		response.Error(c, response.ErrDatabase)
+		return false
+	}
+	if !ok {
+		response.Error(c, response.ErrPermissionDenied, "只能修改自己的博客")
+	}
+	return ok
+}
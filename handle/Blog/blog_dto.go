@@ -0,0 +1,120 @@
+package Blog
+
+import (
+	"time"
+	"xzdp/dal/model"
+)
+
+// 返回给前端的博客结构，字段名使用驼峰命名，匹配前端
+type blogResponse struct {
+	ID         uint64    `json:"id"`
+	ShopID     int64     `json:"shopId"`
+	UserID     uint64    `json:"userId"`
+	Title      string    `json:"title"`
+	Images     string    `json:"images"`
+	Content    string    `json:"content"`
+	Liked      uint32    `json:"liked"`
+	Comments   uint32    `json:"comments"`
+	CreateTime time.Time `json:"createTime"`
+	Name       string    `json:"name"`   // 作者昵称
+	Icon       string    `json:"icon"`   // 作者头像
+	IsLike     bool      `json:"isLike"` // 当前用户是否点过赞
+}
+
+// 发布博客请求结构体
+type publishBlogReq struct {
+	ShopID  int64  `json:"shopId" binding:"required"`
+	Title   string `json:"title" binding:"required,max=255"`
+	Images  string `json:"images" binding:"required,max=2048"` // 多张以","隔开
+	Content string `json:"content" binding:"required,max=2048"`
+}
+
+// 发表评论请求结构体
+type addCommentReq struct {
+	BlogID   uint64 `json:"blogId" binding:"required"`
+	ParentID uint64 `json:"parentId" binding:"omitempty"` // 一级评论为0
+	AnswerID uint64 `json:"answerId" binding:"omitempty"` // 回复的评论id
+	Content  string `json:"content" binding:"required,min=1,max=255"`
+}
+
+func blogModelToResponse(b *model.TbBlog) *blogResponse {
+	return &blogResponse{
+		ID:         b.ID,
+		ShopID:     b.ShopID,
+		UserID:     b.UserID,
+		Title:      b.Title,
+		Images:     b.Images,
+		Content:    b.Content,
+		Liked:      b.Liked,
+		Comments:   b.Comments,
+		CreateTime: b.CreateTime,
+	}
+}
+
+func (r *publishBlogReq) ToModel(userId uint64) *model.TbBlog {
+	return &model.TbBlog{
+		ShopID:  r.ShopID,
+		UserID:  userId,
+		Title:   r.Title,
+		Images:  r.Images,
+		Content: r.Content,
+	}
+}
+
+func (r *addCommentReq) ToModel(userId uint64) *model.TbBlogComment {
+	return &model.TbBlogComment{
+		UserID:   userId,
+		BlogID:   r.BlogID,
+		ParentID: r.ParentID,
+		AnswerID: r.AnswerID,
+		Content:  r.Content,
+	}
+}
+
+// 博客详情中展示的商户信息
+type blogShop struct {
+	ID       uint64 `json:"id"`
+	Name     string `json:"name"`
+	Images   string `json:"images"`
+	Area     string `json:"area"`
+	Address  string `json:"address"`
+	AvgPrice uint64 `json:"avgPrice"`
+	Score    uint32 `json:"score"`
+}
+
+// 博客详情，在列表信息的基础上带上商户信息
+type blogDetailResponse struct {
+	*blogResponse
+	Shop *blogShop `json:"shop,omitempty"`
+}
+
+// 修改博客请求结构体，只有作者本人可以修改
+type updateBlogReq struct {
+	ID      uint64 `json:"id" binding:"required"`
+	ShopID  int64  `json:"shopId" binding:"omitempty"`
+	Title   string `json:"title" binding:"omitempty,max=255"`
+	Images  string `json:"images" binding:"omitempty,max=2048"`
+	Content string `json:"content" binding:"omitempty,max=2048"`
+}
+
+func shopModelToBlogShop(s *model.TbShop) *blogShop {
+	return &blogShop{
+		ID:       s.ID,
+		Name:     s.Name,
+		Images:   s.Images,
+		Area:     s.Area,
+		Address:  s.Address,
+		AvgPrice: s.AvgPrice,
+		Score:    s.Score,
+	}
+}
+
+// ToModel 当通过 struct 更新时，GORM 只会更新非零字段，所以没传的字段不会被修改
+func (r *updateBlogReq) ToModel() *model.TbBlog {
+	return &model.TbBlog{
+		ShopID:  r.ShopID,
+		Title:   r.Title,
+		Images:  r.Images,
+		Content: r.Content,
+	}
+}
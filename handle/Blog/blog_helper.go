@@ -0,0 +1,231 @@
+package Blog
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+	"xzdp/pkg/metrics"
+
+	"github.com/bytedance/sonic"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func getBlogByIdFromDB(ctx context.Context, id uint64) (*model.TbBlog, error) {
+	blogQuery := query.TbBlog
+	return blogQuery.WithContext(ctx).Where(blogQuery.ID.Eq(id)).First()
+}
+
+func createBlogToDB(ctx context.Context, blog *model.TbBlog) error {
+	return query.TbBlog.WithContext(ctx).Create(blog)
+}
+
+// 按照ids的顺序返回博客，数据库中已经不存在的博客会被跳过
+func getBlogsByIdsFromDB(ctx context.Context, ids []uint64) ([]*model.TbBlog, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	blogQuery := query.TbBlog
+	blogs, err := blogQuery.WithContext(ctx).Where(blogQuery.ID.In(ids...)).Find()
+	if err != nil {
+		return nil, err
+	}
+	blogMap := make(map[uint64]*model.TbBlog, len(blogs))
+	for _, b := range blogs {
+		blogMap[b.ID] = b
+	}
+	res := make([]*model.TbBlog, 0, len(blogs))
+	for _, id := range ids {
+		if b, ok := blogMap[id]; ok {
+			res = append(res, b)
+		}
+	}
+	return res, nil
+}
+
+// 填充作者的昵称和头像，一次查询所有作者
+func fillBlogAuthor(ctx context.Context, blogs []*blogResponse) error {
+	if len(blogs) == 0 {
+		return nil
+	}
+	userIds := make([]uint64, 0, len(blogs))
+	for _, b := range blogs {
+		userIds = append(userIds, b.UserID)
+	}
+	userQuery := query.TbUser
+	users, err := userQuery.WithContext(ctx).Select(userQuery.ID, userQuery.NickName, userQuery.Icon).
+		Where(userQuery.ID.In(userIds...)).Find()
+	if err != nil {
+		return err
+	}
+	userMap := make(map[uint64]*model.TbUser, len(users))
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+	for _, b := range blogs {
+		if u, ok := userMap[b.UserID]; ok {
+			b.Name = u.NickName
+			b.Icon = u.Icon
+		}
+	}
+	return nil
+}
+
+// 填充当前用户是否点过赞，未登录用户(userId为0)全部为false，查询失败时按未点赞处理
+func fillBlogIsLike(ctx context.Context, blogs []*blogResponse, userId int64) {
+	if userId == 0 {
+		return
+	}
+	for _, b := range blogs {
+		isLike, err := isBlogLiked(ctx, b.ID, userId)
+		if err != nil {
+			slog.ErrorContext(ctx, "查询点赞状态失败", "blogId", b.ID, "err", err)
+		}
+		b.IsLike = isLike
+	}
+}
+
+// 点赞用户保存在 blog:liked:{blogId} 这个ZSet中，分数是点赞时间，方便按时间查询点赞列表
+func isBlogLiked(ctx context.Context, blogId uint64, userId int64) (bool, error) {
+	_, err := db.RedisDb.ZScore(ctx, blogLikedKeyPrefix+strconv.FormatUint(blogId, 10), strconv.FormatInt(userId, 10)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// 点过赞就取消，没点过就点赞，返回1表示点赞，0表示取消点赞
+// 判断和修改在一个脚本中完成，同一个用户并发点赞时只有一个请求会修改点赞数
+var toggleLikeScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+// 点赞或取消点赞，返回操作之后是否为已点赞状态
+func toggleBlogLike(ctx context.Context, blogId uint64, userId int64) (bool, error) {
+	likedKey := blogLikedKeyPrefix + strconv.FormatUint(blogId, 10)
+	member := strconv.FormatInt(userId, 10)
+	now := time.Now().UnixMilli()
+	//1.先修改Redis中的点赞用户，以它的结果为准
+	res, err := toggleLikeScript.Run(ctx, db.RedisDb, []string{likedKey}, member, now).Int()
+	if err != nil {
+		return false, err
+	}
+	isLike := res == 1
+	//2.再修改数据库中的点赞数，失败时恢复Redis
+	blogQuery := query.TbBlog
+	if isLike {
+		_, err = blogQuery.WithContext(ctx).Where(blogQuery.ID.Eq(blogId)).UpdateColumn(blogQuery.Liked, gorm.Expr("liked + 1"))
+	} else {
+		_, err = blogQuery.WithContext(ctx).Where(blogQuery.ID.Eq(blogId), blogQuery.Liked.Gt(0)).UpdateColumn(blogQuery.Liked, gorm.Expr("liked - 1"))
+	}
+	if err != nil {
+		var undoErr error
+		if isLike {
+			undoErr = db.RedisDb.ZRem(ctx, likedKey, member).Err()
+		} else {
+			undoErr = db.RedisDb.ZAdd(ctx, likedKey, &redis.Z{Score: float64(now), Member: member}).Err()
+		}
+		if undoErr != nil {
+			slog.ErrorContext(ctx, "恢复点赞状态失败", "blogId", blogId, "userId", userId, "err", undoErr)
+		}
+		return !isLike, err
+	}
+	return isLike, nil
+}
+
+// 新增评论，同一个事务中把博客的评论数+1
+func addCommentToDB(ctx context.Context, comment *model.TbBlogComment) error {
+	q := query.Use(db.DBEngine)
+	return q.Transaction(func(tx *query.Query) error {
+		err := tx.TbBlogComment.WithContext(ctx).Create(comment)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TbBlog.WithContext(ctx).Where(tx.TbBlog.ID.Eq(comment.BlogID)).UpdateColumn(tx.TbBlog.Comments, gorm.Expr("comments + 1"))
+		return err
+	})
+}
+
+// 游标分页查询某个用户的博客，按id倒序，lastId为0表示第一页
+// 用id做游标而不是offset，翻页期间有新博客发布也不会出现重复数据
+func getBlogsByUserFromDB(ctx context.Context, userId uint64, lastId uint64) ([]*model.TbBlog, error) {
+	blogQuery := query.TbBlog
+	do := blogQuery.WithContext(ctx).Where(blogQuery.UserID.Eq(userId))
+	if lastId > 0 {
+		do = do.Where(blogQuery.ID.Lt(lastId))
+	}
+	return do.Order(blogQuery.ID.Desc()).Limit(userBlogPageSize).Find()
+}
+
+func getShopForBlog(ctx context.Context, shopId int64) (*model.TbShop, error) {
+	shopQuery := query.TbShop
+	return shopQuery.WithContext(ctx).Where(shopQuery.ID.Eq(uint64(shopId))).First()
+}
+
+// 博客详情缓存只保存博客本身和作者、商户信息，点赞状态因人而异，每次单独查询
+func getBlogDetailFromCache(ctx context.Context, blogId uint64) (*blogDetailResponse, error) {
+	res, err := db.RedisDb.Get(ctx, blogCacheKeyPrefix+strconv.FormatUint(blogId, 10)).Result()
+	metrics.CacheLookup(blogCacheKeyPrefix, res != "" && err == nil)
+	if res == "" || err != nil {
+		return nil, err
+	}
+	var blog blogDetailResponse
+	err = sonic.Unmarshal([]byte(res), &blog)
+	if err != nil {
+		return nil, err
+	}
+	return &blog, nil
+}
+
+func setBlogDetailToCache(ctx context.Context, blog *blogDetailResponse) error {
+	b, err := sonic.Marshal(blog)
+	if err != nil {
+		return err
+	}
+	return db.RedisDb.Set(ctx, blogCacheKeyPrefix+strconv.FormatUint(blog.ID, 10), b, config.Current().Cache.Blog).Err()
+}
+
+func deleteBlogDetailFromCache(ctx context.Context, blogId uint64) error {
+	return db.RedisDb.Del(ctx, blogCacheKeyPrefix+strconv.FormatUint(blogId, 10)).Err()
+}
+
+func updateBlogToDB(ctx context.Context, blogId uint64, blog *model.TbBlog) error {
+	blogQuery := query.TbBlog
+	_, err := blogQuery.WithContext(ctx).Where(blogQuery.ID.Eq(blogId)).Updates(blog)
+	return err
+}
+
+// 删除博客，同一个事务中级联删除它的所有评论
+func deleteBlogFromDB(ctx context.Context, blogId uint64) error {
+	q := query.Use(db.DBEngine)
+	return q.Transaction(func(tx *query.Query) error {
+		_, err := tx.TbBlogComment.WithContext(ctx).Where(tx.TbBlogComment.BlogID.Eq(blogId)).Delete()
+		if err != nil {
+			return err
+		}
+		_, err = tx.TbBlog.WithContext(ctx).Where(tx.TbBlog.ID.Eq(blogId)).Delete()
+		return err
+	})
+}
+
+// 删除博客相关的Redis数据：详情缓存、点赞列表、排行榜
+func deleteBlogFromRedis(ctx context.Context, blogId uint64) error {
+	// 几个key在集群模式下不在同一个slot，每条命令只操作一个key，Exec返回第一个失败的命令的错误
+	id := strconv.FormatUint(blogId, 10)
+	pipe := db.RedisDb.Pipeline()
+	pipe.Del(ctx, blogCacheKeyPrefix+id)
+	pipe.Del(ctx, blogLikedKeyPrefix+id)
+	pipe.ZRem(ctx, hotRankKey, id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
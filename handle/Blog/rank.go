@@ -0,0 +1,116 @@
+package Blog
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 热门博客排行榜：用Redis的ZSet保存 博客id -> 热度分
+// 点赞、评论的时候只重新计算这一篇博客的分数，不需要定时全量重算，
+// 因为时间衰减是通过"发布时间越晚基础分越高"实现的，老博客的分数不会变，只是被新博客超过
+
+// 热度计算的起始时间，只要固定不变即可
+var hotRankEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+
+// 计算博客热度
+func hotScore(b *model.TbBlog) float64 {
+	opt := config.Current().HotBlog
+	interaction := float64(b.Liked)*opt.LikeWeight + float64(b.Comments)*opt.CommentWeight
+	// 互动量取对数：前10个赞和后面90个赞的权重一样，避免老的爆款长期霸榜
+	order := math.Log10(math.Max(interaction, 1))
+	decay := opt.DecayHours
+	if decay <= 0 {
+		decay = 12
+	}
+	age := b.CreateTime.Sub(hotRankEpoch).Hours() / decay
+	return order + age
+}
+
+// 更新某篇博客在排行榜中的分数
+func updateHotRank(ctx context.Context, b *model.TbBlog) error {
+	err := db.RedisDb.ZAdd(ctx, hotRankKey, &redis.Z{
+		Score:  hotScore(b),
+		Member: strconv.FormatUint(b.ID, 10),
+	}).Err()
+	if err != nil {
+		return err
+	}
+	// 只保留前RankSize名，分数最低的排在最前面，所以删掉[0, -(RankSize+1)]
+	return db.RedisDb.ZRemRangeByRank(ctx, hotRankKey, 0, -(config.Current().HotBlog.RankSize + 1)).Err()
+}
+
+// 还没有从数据库重建过时（第一次启动或者Redis数据丢失）从数据库重建
+// 不能用排行榜是否存在来判断：点赞、评论、发布会先往空的排行榜里写入一篇博客
+func rebuildHotRank(ctx context.Context) error {
+	size := int(config.Current().HotBlog.RankSize)
+	blogQuery := query.TbBlog
+	// 候选集：最新发布的 + 点赞最多的，其他博客的热度不可能进入前RankSize名
+	latest, err := blogQuery.WithContext(ctx).Order(blogQuery.CreateTime.Desc()).Limit(size).Find()
+	if err != nil {
+		return err
+	}
+	mostLiked, err := blogQuery.WithContext(ctx).Order(blogQuery.Liked.Desc()).Limit(size).Find()
+	if err != nil {
+		return err
+	}
+	members := make([]*redis.Z, 0, len(latest)+len(mostLiked))
+	for _, b := range append(latest, mostLiked...) {
+		members = append(members, &redis.Z{Score: hotScore(b), Member: strconv.FormatUint(b.ID, 10)})
+	}
+	if len(members) > 0 {
+		err = db.RedisDb.ZAdd(ctx, hotRankKey, members...).Err()
+		if err != nil {
+			return err
+		}
+		err = db.RedisDb.ZRemRangeByRank(ctx, hotRankKey, 0, -(config.Current().HotBlog.RankSize + 1)).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return db.RedisDb.Set(ctx, hotRankRebuiltKey, 1, 0).Err()
+}
+
+// ResetHotRank 删除排行榜，下一次查询时从数据库重建，批量导入博客后调用
+// 两个key在集群模式下不在同一个slot，分开删除：先删排行榜再删重建标记，
+// 反过来的话中间的查询重建完排行榜后又被删掉，重建标记却还在，排行榜会一直是空的
+func ResetHotRank(ctx context.Context) error {
+	if err := db.RedisDb.Del(ctx, hotRankKey).Err(); err != nil {
+		return err
+	}
+	return db.RedisDb.Del(ctx, hotRankRebuiltKey).Err()
+}
+
+// 分页获取排行榜中的博客id，current从1开始
+func getHotBlogIds(ctx context.Context, current, pageSize int64) ([]uint64, error) {
+	exists, err := db.RedisDb.Exists(ctx, hotRankRebuiltKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		if err = rebuildHotRank(ctx); err != nil {
+			return nil, err
+		}
+	}
+	start := (current - 1) * pageSize
+	res, err := db.RedisDb.ZRevRange(ctx, hotRankKey, start, start+pageSize-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(res))
+	for _, member := range res {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
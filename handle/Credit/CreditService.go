@@ -0,0 +1,65 @@
+package Credit
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"xzdp/middleware"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 积分来源，写入tb_credit_log.reason
+const (
+	ReasonSign   = "sign"
+	ReasonStreak = "streak"
+	ReasonBlog   = "blog"
+	ReasonLiked  = "liked"
+)
+
+const (
+	maxLevel            = 9
+	creditLogPageSize   = 20
+	likedAwardKeyPrefix = "credit:liked:"
+)
+
+// GET /api/credit
+func GetMyCredit(c *gin.Context) {
+	userId := uint64(c.GetInt64(middleware.CtxKeyUserId))
+	info, err := getUserInfoFromDB(c, userId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.ErrorContext(c, "查询用户积分失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	res := creditResponse{}
+	if info != nil {
+		res.Credits = info.Credits
+		res.Level = info.Level
+	}
+	res.NextLevelCredits = nextLevelCredits(res.Level)
+	response.Success(c, res)
+}
+
+// GET /api/credit/logs?lastId=
+func GetCreditLogs(c *gin.Context) {
+	lastId, err := strconv.ParseUint(c.DefaultQuery("lastId", "0"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "无效的lastId")
+		return
+	}
+	userId := uint64(c.GetInt64(middleware.CtxKeyUserId))
+	logs, err := getCreditLogsFromDB(c, userId, lastId)
+	if err != nil {
+		slog.ErrorContext(c, "查询积分流水失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	res := make([]creditLogResponse, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, creditLogModelToResponse(l))
+	}
+	response.Success(c, res)
+}
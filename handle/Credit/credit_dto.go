@@ -0,0 +1,32 @@
+package Credit
+
+import (
+	"time"
+	"xzdp/dal/model"
+)
+
+type creditResponse struct {
+	Credits          uint32 `json:"credits"`
+	Level            uint32 `json:"level"`
+	NextLevelCredits uint32 `json:"nextLevelCredits"` // 升到下一级需要的累计积分，0表示已经是最高级
+}
+
+type creditLogResponse struct {
+	ID         uint64    `json:"id"`
+	Amount     int32     `json:"amount"`
+	Balance    uint32    `json:"balance"`
+	Reason     string    `json:"reason"`
+	RefID      uint64    `json:"refId"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+func creditLogModelToResponse(l *model.TbCreditLog) creditLogResponse {
+	return creditLogResponse{
+		ID:         l.ID,
+		Amount:     l.Amount,
+		Balance:    l.Balance,
+		Reason:     l.Reason,
+		RefID:      l.RefID,
+		CreateTime: l.CreateTime,
+	}
+}
@@ -0,0 +1,150 @@
+package Credit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+	"xzdp/handle/User/UserCache"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 根据累计积分计算会员等级，0代表未开通会员
+func calcLevel(credits uint32) uint32 {
+	var level uint32
+	for i, threshold := range config.Current().Credit.Levels {
+		if credits < threshold || i >= maxLevel {
+			break
+		}
+		level = uint32(i + 1)
+	}
+	return level
+}
+
+// 升到下一级还需要的累计积分，已经是最高级时返回0
+func nextLevelCredits(level uint32) uint32 {
+	levels := config.Current().Credit.Levels
+	if int(level) >= len(levels) || level >= maxLevel {
+		return 0
+	}
+	return levels[level]
+}
+
+// Award 变动用户积分，写入积分流水并重新计算会员等级
+func Award(ctx context.Context, userId uint64, reason string, amount int32, refId uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	var oldLevel, newLevel uint32
+	q := query.Use(db.DBEngine)
+	err := q.Transaction(func(tx *query.Query) error {
+		info := tx.TbUserInfo
+		//1.锁住用户详情行，避免并发加积分时互相覆盖；用户详情不存在时先创建
+		userInfo, err := info.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(info.UserID.Eq(userId)).First()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			userInfo = &model.TbUserInfo{UserID: userId}
+			err = info.WithContext(ctx).Omit(info.Birthday).Create(userInfo)
+		}
+		if err != nil {
+			return err
+		}
+		//2.计算新的积分和等级，积分不会扣成负数
+		credits := int64(userInfo.Credits) + int64(amount)
+		if credits < 0 {
+			credits = 0
+		}
+		oldLevel = userInfo.Level
+		newLevel = calcLevel(uint32(credits))
+		_, err = info.WithContext(ctx).Where(info.UserID.Eq(userId)).
+			UpdateSimple(info.Credits.Value(uint32(credits)), info.Level.Value(newLevel))
+		if err != nil {
+			return err
+		}
+		//3.写积分流水
+		return tx.TbCreditLog.WithContext(ctx).Create(&model.TbCreditLog{
+			UserID:  userId,
+			Amount:  amount,
+			Balance: uint32(credits),
+			Reason:  reason,
+			RefID:   refId,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	// 用户资料缓存中有积分和等级，变动后删除
+	UserCache.DeleteInfo(ctx, userId)
+	if newLevel > oldLevel {
+		slog.InfoContext(ctx, "会员升级", "userId", userId, "from", oldLevel, "to", newLevel)
+	}
+	return nil
+}
+
+// AwardSign 签到奖励，连续签到达到配置的天数时额外奖励
+func AwardSign(ctx context.Context, userId int64, streak int) {
+	if err := Award(ctx, uint64(userId), ReasonSign, config.Current().Credit.Sign, 0); err != nil {
+		slog.ErrorContext(ctx, "发放签到积分失败", "userId", userId, "err", err)
+	}
+	if bonus, ok := config.Current().Credit.Streak[streak]; ok {
+		if err := Award(ctx, uint64(userId), ReasonStreak, bonus, uint64(streak)); err != nil {
+			slog.ErrorContext(ctx, "发放连续签到积分失败", "userId", userId, "streak", streak, "err", err)
+		}
+	}
+}
+
+// AwardBlog 发布博客奖励
+func AwardBlog(ctx context.Context, userId uint64, blogId uint64) {
+	if err := Award(ctx, userId, ReasonBlog, config.Current().Credit.Blog, blogId); err != nil {
+		slog.ErrorContext(ctx, "发放发布博客积分失败", "userId", userId, "blogId", blogId, "err", err)
+	}
+}
+
+// AwardBackupSign 补签奖励，只发放基础积分，补签不能触发连续签到的额外奖励
+func AwardBackupSign(ctx context.Context, userId int64) {
+	if err := Award(ctx, uint64(userId), ReasonSign, config.Current().Credit.Sign, 0); err != nil {
+		slog.ErrorContext(ctx, "发放补签积分失败", "userId", userId, "err", err)
+	}
+}
+
+// AwardLiked 博客被点赞时奖励作者，同一个用户对同一篇博客反复点赞只奖励一次，给自己点赞不奖励
+// 去重记录不设置过期时间，否则过期后取消再点赞又会奖励一次；博客删除时由ClearLikedAwards清理
+func AwardLiked(ctx context.Context, authorId uint64, blogId uint64, likerId int64) {
+	if authorId == uint64(likerId) {
+		return
+	}
+	key := likedAwardKeyPrefix + strconv.FormatUint(blogId, 10)
+	added, err := db.RedisDb.SAdd(ctx, key, likerId).Result()
+	if err != nil || added == 0 {
+		return
+	}
+	if err = Award(ctx, authorId, ReasonLiked, config.Current().Credit.Liked, blogId); err != nil {
+		slog.ErrorContext(ctx, "发放点赞积分失败", "userId", authorId, "blogId", blogId, "err", err)
+	}
+}
+
+// ClearLikedAwards 删除博客时清理点赞奖励的去重记录
+func ClearLikedAwards(ctx context.Context, blogId uint64) error {
+	return db.RedisDb.Del(ctx, likedAwardKeyPrefix+strconv.FormatUint(blogId, 10)).Err()
+}
+
+// 游标分页查询积分流水，按id倒序
+func getCreditLogsFromDB(ctx context.Context, userId uint64, lastId uint64) ([]*model.TbCreditLog, error) {
+	logQuery := query.TbCreditLog
+	do := logQuery.WithContext(ctx).Where(logQuery.UserID.Eq(userId))
+	if lastId > 0 {
+		do = do.Where(logQuery.ID.Lt(lastId))
+	}
+	return do.Order(logQuery.ID.Desc()).Limit(creditLogPageSize).Find()
+}
+
+func getUserInfoFromDB(ctx context.Context, userId uint64) (*model.TbUserInfo, error) {
+	infoQuery := query.TbUserInfo
+	return infoQuery.WithContext(ctx).Where(infoQuery.UserID.Eq(userId)).First()
+}
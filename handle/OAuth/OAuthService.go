@@ -0,0 +1,193 @@
+package OAuth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/handle/User"
+	"xzdp/pkg/oidc"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 第三方登录（OAuth2授权码 + PKCE，身份提供方需要支持OIDC）：
+// 1. GET /api/oauth/:provider/login 跳转到身份提供方
+// 2. 身份提供方回调 GET /api/oauth/:provider/callback，已经绑定过的第三方账号直接登录
+// 3. 第一次登录时返回ticket，前端获取验证码后调用 POST /api/oauth/bind 绑定手机号并登录
+const (
+	stateKeyPrefix = "oauth:state:" // oauth:state:{state} -> authState
+	bindKeyPrefix  = "oauth:bind:"  // oauth:bind:{ticket} -> bindTicket
+)
+
+var providers = map[string]*oidc.Provider{}
+
+// InitProviders 根据配置创建身份提供方
+func InitProviders(settings []config.OAuthProviderSetting) {
+	providers = make(map[string]*oidc.Provider, len(settings))
+	for _, s := range settings {
+		providers[s.Name] = oidc.NewProvider(oidc.Config{
+			Name:         s.Name,
+			ClientID:     s.ClientID,
+			ClientSecret: s.ClientSecret,
+			AuthURL:      s.AuthURL,
+			TokenURL:     s.TokenURL,
+			JWKSURL:      s.JWKSURL,
+			Issuer:       s.Issuer,
+			RedirectURL:  s.RedirectURL,
+			Scopes:       s.Scopes,
+		})
+	}
+}
+
+func getProvider(c *gin.Context) (*oidc.Provider, bool) {
+	p, ok := providers[c.Param("provider")]
+	if !ok {
+		response.Error(c, response.ErrNotFound, "不支持的登录方式")
+	}
+	return p, ok
+}
+
+// GET /api/oauth/:provider/login
+func Login(c *gin.Context) {
+	provider, ok := getProvider(c)
+	if !ok {
+		return
+	}
+	//1.生成state、nonce和PKCE的code_verifier
+	state, err1 := oidc.RandomString()
+	nonce, err2 := oidc.RandomString()
+	verifier, err3 := oidc.RandomString()
+	if err := errors.Join(err1, err2, err3); err != nil {
+		slog.ErrorContext(c, "生成第三方登录参数失败", "err", err)
+		response.Error(c, response.ErrUnknown)
+		return
+	}
+	//2.保存到Redis，回调时校验
+	err := setJSON(c, stateKeyPrefix+state, authState{Provider: provider.Name(), Verifier: verifier, Nonce: nonce},
+		config.OAuthOption.StateTTL)
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	//3.跳转到身份提供方
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state, nonce, verifier))
+}
+
+// GET /api/oauth/:provider/callback?code=&state=
+func Callback(c *gin.Context) {
+	provider, ok := getProvider(c)
+	if !ok {
+		return
+	}
+	if errMsg := c.Query("error"); errMsg != "" {
+		response.Error(c, response.ErrValidation, "第三方登录已取消："+errMsg)
+		return
+	}
+	//1.校验state，每个state只能使用一次
+	var st authState
+	err := getJSON(c, stateKeyPrefix+c.Query("state"), &st, true)
+	if errors.Is(err, errNotFound) || (err == nil && st.Provider != provider.Name()) {
+		response.Error(c, response.ErrExpired, "登录请求已过期，请重新登录")
+		return
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	//2.用授权码换令牌并验证id_token
+	token, err := provider.Exchange(c, c.Query("code"), st.Verifier)
+	if err != nil {
+		slog.WarnContext(c, "第三方登录换取令牌失败", "provider", provider.Name(), "err", err)
+		response.Error(c, response.ErrTokenInvalid, "第三方登录失败")
+		return
+	}
+	claims, err := provider.VerifyIDToken(c, token.IDToken, st.Nonce)
+	if err != nil {
+		slog.WarnContext(c, "第三方登录id_token无效", "provider", provider.Name(), "err", err)
+		response.Error(c, response.ErrTokenInvalid, "第三方登录失败")
+		return
+	}
+	//3.已经绑定过直接登录
+	identity, err := getIdentityFromDB(c, provider.Name(), claims.Subject)
+	if err == nil {
+		user, err := getUserFromDB(c, identity.UserID)
+		if err != nil {
+			response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+			return
+		}
+		User.RespondLogin(c, user)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	//4.第一次登录，需要绑定手机号
+	ticket, err := oidc.RandomString()
+	if err == nil {
+		err = setJSON(c, bindKeyPrefix+ticket, bindTicket{Provider: provider.Name(), Subject: claims.Subject, Email: claims.Email},
+			config.OAuthOption.BindTTL)
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	response.Success(c, bindRequiredResponse{NeedBind: true, Ticket: ticket, Email: claims.Email})
+}
+
+// POST /api/oauth/bind 第一次第三方登录时绑定手机号，手机号没有注册过时自动注册
+func BindPhone(c *gin.Context) {
+	var req bindPhoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	//1.取出第三方账号信息，绑定成功后才删除，验证码输错时可以重试
+	var ticket bindTicket
+	err := getJSON(c, bindKeyPrefix+req.Ticket, &ticket, false)
+	if errors.Is(err, errNotFound) {
+		response.Error(c, response.ErrExpired, "绑定已过期，请重新登录")
+		return
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	//2.走验证码登录的流程校验手机号
+	user, ok := User.LoginWithCode(c, req.Phone, req.Code)
+	if !ok {
+		return
+	}
+	//3.一个手机号对同一个身份提供方只能绑定一个账号
+	_, err = getIdentityOfUserFromDB(c, user.ID, ticket.Provider)
+	if err == nil {
+		response.Error(c, response.ErrValidation, "该手机号已经绑定了其他"+ticket.Provider+"账号")
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	err = createIdentityToDB(c, &model.TbUserIdentity{
+		UserID:   user.ID,
+		Provider: ticket.Provider,
+		Subject:  ticket.Subject,
+		Email:    ticket.Email,
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		response.Error(c, response.ErrValidation, "该第三方账号已经绑定了其他手机号")
+		return
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "绑定失败"))
+		return
+	}
+	_ = deleteKey(c, bindKeyPrefix+req.Ticket)
+	slog.InfoContext(c, "绑定第三方账号", "userId", user.ID, "provider", ticket.Provider)
+	//4.登录
+	User.RespondLogin(c, user)
+}
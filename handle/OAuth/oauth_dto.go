@@ -0,0 +1,28 @@
+package OAuth
+
+// 跳转到身份提供方前保存的信息，回调时取出校验
+type authState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"` // PKCE的code_verifier
+	Nonce    string `json:"nonce"`
+}
+
+// 第一次使用第三方账号登录时需要绑定手机号，绑定完成前保存第三方账号的信息
+type bindTicket struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+}
+
+// 绑定手机号请求结构体，验证码通过 /api/user/code 获取
+type bindPhoneReq struct {
+	Ticket string `json:"ticket" binding:"required"`
+	Phone  string `json:"phone" binding:"required"`
+	Code   string `json:"code" binding:"required,min=4,max=6"`
+}
+
+type bindRequiredResponse struct {
+	NeedBind bool   `json:"needBind"`
+	Ticket   string `json:"ticket"` // 调用 /api/oauth/bind 时带上
+	Email    string `json:"email,omitempty"`
+}
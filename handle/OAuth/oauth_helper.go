@@ -0,0 +1,64 @@
+package OAuth
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+
+	"github.com/bytedance/sonic"
+	"github.com/go-redis/redis/v8"
+)
+
+var errNotFound = errors.New("not found")
+
+func setJSON(ctx context.Context, key string, v any, ttl time.Duration) error {
+	b, err := sonic.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return db.RedisDb.Set(ctx, key, b, ttl).Err()
+}
+
+// 读取Redis中的json，del为true时读取后删除，保证只能使用一次
+func getJSON(ctx context.Context, key string, v any, del bool) error {
+	var res string
+	var err error
+	if del {
+		res, err = db.RedisDb.GetDel(ctx, key).Result()
+	} else {
+		res, err = db.RedisDb.Get(ctx, key).Result()
+	}
+	if errors.Is(err, redis.Nil) {
+		return errNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return sonic.Unmarshal([]byte(res), v)
+}
+
+func getIdentityFromDB(ctx context.Context, provider, subject string) (*model.TbUserIdentity, error) {
+	i := query.TbUserIdentity
+	return i.WithContext(ctx).Where(i.Provider.Eq(provider), i.Subject.Eq(subject)).First()
+}
+
+func getIdentityOfUserFromDB(ctx context.Context, userId uint64, provider string) (*model.TbUserIdentity, error) {
+	i := query.TbUserIdentity
+	return i.WithContext(ctx).Where(i.UserID.Eq(userId), i.Provider.Eq(provider)).First()
+}
+
+func createIdentityToDB(ctx context.Context, identity *model.TbUserIdentity) error {
+	return query.TbUserIdentity.WithContext(ctx).Create(identity)
+}
+
+func getUserFromDB(ctx context.Context, userId uint64) (*model.TbUser, error) {
+	u := query.TbUser
+	return u.WithContext(ctx).Where(u.ID.Eq(userId)).First()
+}
+
+func deleteKey(ctx context.Context, key string) error {
+	return db.RedisDb.Del(ctx, key).Err()
+}
@@ -0,0 +1,279 @@
+package OAuth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"xzdp/config"
+	"xzdp/db"
+	"xzdp/db/migrate"
+	"xzdp/handle/User"
+	"xzdp/middleware"
+	"xzdp/pkg/oidc/oidctest"
+	"xzdp/pkg/response"
+	"xzdp/pkg/sms"
+
+	"github.com/alicebob/miniredis/v2"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// 第三方登录的完整流程：进程内的MySQL（go-mysql-server）、Redis（miniredis）和身份提供方（oidctest）
+
+const (
+	testProvider = "mock"
+	testClientID = "xzdp"
+	redirectURL  = "http://localhost/api/oauth/mock/callback"
+	testDBName   = "xzdp"
+)
+
+var (
+	idp    *oidctest.Server
+	engine *gin.Engine
+)
+
+func TestMain(m *testing.M) {
+	if err := setup(); err != nil {
+		fmt.Fprintln(os.Stderr, "setup:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	idp.Close()
+	os.Exit(code)
+}
+
+func setup() error {
+	gin.SetMode(gin.TestMode)
+	//1.MySQL
+	mysqlAddr, err := startMySQL()
+	if err != nil {
+		return err
+	}
+	config.InitConfig("../../configs/config.yaml", []string{
+		"mysql.host=" + mysqlAddr,
+		"mysql.password=",
+		"mysql.dbname=" + testDBName,
+	})
+	if db.DBEngine, err = db.NewMySQL(config.MysqlOption); err != nil {
+		return err
+	}
+	sqlDB, err := db.DBEngine.DB()
+	if err != nil {
+		return err
+	}
+	if _, err = migrate.Up(context.Background(), sqlDB); err != nil {
+		return err
+	}
+	//2.Redis
+	mr, err := miniredis.Run()
+	if err != nil {
+		return err
+	}
+	db.RedisDb = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	//3.令牌、短信和身份提供方
+	if err = middleware.InitKeySet(config.JwtOption); err != nil {
+		return err
+	}
+	sender, err := sms.NewSender("log", "", "", "", 0)
+	if err != nil {
+		return err
+	}
+	sms.InitSMS(sender, config.SMSOption.Templates)
+	idp = oidctest.NewServer(testClientID, "subject-1")
+	cfg := idp.Config(testProvider, redirectURL)
+	InitProviders([]config.OAuthProviderSetting{{
+		Name:        cfg.Name,
+		ClientID:    cfg.ClientID,
+		AuthURL:     cfg.AuthURL,
+		TokenURL:    cfg.TokenURL,
+		JWKSURL:     cfg.JWKSURL,
+		Issuer:      cfg.Issuer,
+		RedirectURL: cfg.RedirectURL,
+		Scopes:      cfg.Scopes,
+	}})
+	//4.路由，与router中的一致
+	engine = gin.New()
+	api := engine.Group("/api")
+	api.POST("/user/code", User.SendVerifyCode)
+	api.GET("/oauth/:provider/login", Login)
+	api.GET("/oauth/:provider/callback", Callback)
+	api.POST("/oauth/bind", BindPhone)
+	return nil
+}
+
+func startMySQL() (string, error) {
+	pro := memory.NewDBProvider(memory.NewDatabase(testDBName))
+	cfg := server.Config{Protocol: "tcp", Address: "127.0.0.1:0"}
+	s, err := server.NewServer(cfg, sqle.NewDefault(pro), sql.NewContext, memory.NewSessionBuilder(pro), nil)
+	if err != nil {
+		return "", err
+	}
+	go s.Start()
+	return s.Listener.Addr().(*net.TCPAddr).String(), nil
+}
+
+type result struct {
+	Code int             `json:"code"`
+	Data json.RawMessage `json:"data"`
+}
+
+func do(t *testing.T, method, target string, body any) result {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	var res result
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s %s: status %d, body %q", method, target, w.Code, w.Body.String())
+	}
+	return res
+}
+
+// 跳转到身份提供方的地址
+func login(t *testing.T) *url.URL {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/oauth/"+testProvider+"/login", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("login: status %d, body %q", w.Code, w.Body.String())
+	}
+	u, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// 访问身份提供方的授权地址，返回它回调的地址
+func authorize(t *testing.T, authURL *url.URL) *url.URL {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", resp.StatusCode)
+	}
+	u, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func callback(t *testing.T, callbackURL *url.URL) result {
+	t.Helper()
+	return do(t, http.MethodGet, callbackURL.RequestURI(), nil)
+}
+
+func expectCode(t *testing.T, res result, code int) {
+	t.Helper()
+	if res.Code != code {
+		t.Fatalf("code = %d, want %d, data %s", res.Code, code, res.Data)
+	}
+}
+
+type loginData struct {
+	Token string `json:"token"`
+	User  struct {
+		ID uint64 `json:"id"`
+	} `json:"user"`
+}
+
+func TestLoginBindAndRelogin(t *testing.T) {
+	idp.SetUser("subject-bind", "bind@example.com")
+	//1.第一次登录需要绑定手机号
+	res := callback(t, authorize(t, login(t)))
+	expectCode(t, res, response.ErrSuccess)
+	var bind bindRequiredResponse
+	if err := json.Unmarshal(res.Data, &bind); err != nil {
+		t.Fatal(err)
+	}
+	if !bind.NeedBind || bind.Ticket == "" || bind.Email != "bind@example.com" {
+		t.Fatalf("callback = %+v, want a bind ticket", bind)
+	}
+	//2.获取验证码，开发环境会直接返回
+	phone := "13800000001"
+	res = do(t, http.MethodPost, "/api/user/code?phone="+phone, nil)
+	expectCode(t, res, response.ErrSuccess)
+	var sent struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(res.Data, &sent); err != nil || sent.Code == "" {
+		t.Fatalf("send code: %s", res.Data)
+	}
+	//3.绑定后登录
+	res = do(t, http.MethodPost, "/api/oauth/bind", bindPhoneReq{Ticket: bind.Ticket, Phone: phone, Code: sent.Code})
+	expectCode(t, res, response.ErrSuccess)
+	var bound loginData
+	if err := json.Unmarshal(res.Data, &bound); err != nil || bound.Token == "" {
+		t.Fatalf("bind: %s", res.Data)
+	}
+	//4.ticket只能使用一次
+	res = do(t, http.MethodPost, "/api/oauth/bind", bindPhoneReq{Ticket: bind.Ticket, Phone: phone, Code: sent.Code})
+	expectCode(t, res, response.ErrExpired)
+	//5.再次登录直接进入绑定的账号
+	res = callback(t, authorize(t, login(t)))
+	expectCode(t, res, response.ErrSuccess)
+	var relogin loginData
+	if err := json.Unmarshal(res.Data, &relogin); err != nil || relogin.Token == "" {
+		t.Fatalf("relogin: %s", res.Data)
+	}
+	if relogin.User.ID != bound.User.ID {
+		t.Fatalf("relogin user = %d, want %d", relogin.User.ID, bound.User.ID)
+	}
+}
+
+func TestCallbackBadState(t *testing.T) {
+	idp.SetUser("subject-state", "")
+	cb := authorize(t, login(t))
+	//1.伪造的state
+	forged := *cb
+	q := forged.Query()
+	q.Set("state", "forged")
+	forged.RawQuery = q.Encode()
+	expectCode(t, callback(t, &forged), response.ErrExpired)
+	//2.state只能使用一次
+	expectCode(t, callback(t, cb), response.ErrSuccess)
+	expectCode(t, callback(t, cb), response.ErrExpired)
+}
+
+func TestCallbackNonceMismatch(t *testing.T) {
+	idp.SetUser("subject-nonce", "")
+	//身份提供方签发的id_token带着被替换的nonce
+	authURL := login(t)
+	q := authURL.Query()
+	q.Set("nonce", "tampered")
+	authURL.RawQuery = q.Encode()
+	expectCode(t, callback(t, authorize(t, authURL)), response.ErrTokenInvalid)
+}
+
+func TestCallbackWrongAudience(t *testing.T) {
+	idp.SetUser("subject-aud", "")
+	idp.SetAudience("other-client")
+	defer idp.SetAudience("")
+	expectCode(t, callback(t, authorize(t, login(t))), response.ErrTokenInvalid)
+}
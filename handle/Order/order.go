@@ -7,7 +7,10 @@ import (
 	"xzdp/dal/model"
 	"xzdp/dal/query"
 	"xzdp/db"
+	"xzdp/middleware"
+	"xzdp/pkg/metrics"
 	"xzdp/pkg/response"
+	"xzdp/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,24 +28,18 @@ type seckillRequest struct {
 	VoucherId int `json:"voucherId"`
 }
 
-// 压测时候用
-type reqBody struct {
-	UserId int64 `json:"userId" binding:"omitempty"`
-}
-
 const (
 	SeckillVoucherKeyPrefix = "SeckillVoucher:"
-	SeckillVoucherTTL       = 30 * time.Minute
 )
 
 // 生成分布式订单ID
-func generateOrderId(KeyPrefix string) int64 {
+func generateOrderId(ctx context.Context, KeyPrefix string) int64 {
 	now := time.Now().Unix()
 	// 生产序列号
 	// Go语言的时间格式是通过一个特定的参考时间来定义的
 	// 这个参考时间是Mon Jan 2 15:04:05 MST 2006
 	date := time.Now().Format("2006:01:01")
-	count, err := db.RedisDb.Incr(context.Background(), incr+KeyPrefix+":"+date).Result()
+	count, err := db.RedisDb.Incr(ctx, incr+KeyPrefix+":"+date).Result()
 	if err != nil {
 		return -1
 	}
@@ -54,37 +51,32 @@ func SeckillVouchers(c *gin.Context) {
 	voucherIdStr := c.Param("id")
 	voucherIdInt, err := strconv.Atoi(voucherIdStr)
 	if err != nil {
-		response.HandleBusinessError(c, err)
+		response.Error(c, response.ErrValidation, "优惠券id格式错误")
+		return
 	}
-	// userId := c.GetInt64(middleware.CtxKeyUserId)
-
-	//-----------------------------------------------
-	var reqbody reqBody
+	userId := c.GetInt64(middleware.CtxKeyUserId)
 	seckill := query.TbSeckillVoucher
-	CacheKey := SeckillVoucherKeyPrefix + voucherIdStr
-	err = c.BindJSON(&reqbody)
-	if err != nil {
-		response.HandleBusinessError(c, err)
-	}
-	userId := reqbody.UserId
-	//------------------------------------------------
-	// 先判断是否已经拥有了优惠券
+	CacheKey := seckillStockKey(voucherIdStr)
+	metrics.Seckill(metrics.SeckillAttempt)
+	// 先判断是否已经拥有了优惠券，读主库，从库延迟会导致重复下单
 	order := query.TbVoucherOrder
-	res, err := order.Where(order.UserID.Eq(uint64(userId))).Find()
+	res, err := order.WithContext(c).WriteDB().Where(order.UserID.Eq(uint64(userId))).Find()
 	if len(res) > 0 {
+		metrics.Seckill(metrics.SeckillDuplicate)
 		response.Error(c, response.ErrValidation, "每个用户限购一张该优惠券")
 		return
 	}
 	// 1.判断是否已经过期
 	reqTime := time.Now()
 	//这里就不能用helper里面的方法了，因为里面的方法需要在事务下进行
-	CacheStock := GetStockfromCache(CacheKey)
+	CacheStock := GetStockfromCache(c, CacheKey)
 	if CacheStock < 0 {
-		seckill, err := seckill.Where(seckill.VoucherID.Eq(uint64(voucherIdInt))).First()
+		// 库存要写入缓存，读主库
+		seckill, err := seckill.WithContext(c).WriteDB().Where(seckill.VoucherID.Eq(uint64(voucherIdInt))).First()
 		if seckill == nil || err != nil {
 			return
 		}
-		SetSeckillStockToCache(CacheKey, int(seckill.Stock))
+		SetSeckillStockToCache(c, CacheKey, int(seckill.Stock))
 		// 1.再判断库存
 		if reqTime.After(seckill.EndTime) || reqTime.Before(seckill.BeginTime) {
 			response.Error(c, response.ErrValidation, "不在秒杀优惠券时间范围内")
@@ -92,33 +84,44 @@ func SeckillVouchers(c *gin.Context) {
 		}
 		// 2.再判断库存
 		if seckill.Stock <= 0 {
+			metrics.Seckill(metrics.SeckillSoldOut)
 			response.Error(c, response.ErrValidation, "优惠券已经没啦，下次再快一点")
 			return
 		}
 	}
-	// DECR原子减1，返回减后的值（避免并发问题）
-	remainStock, err := db.RedisDb.Decr(context.Background(), SeckillVoucherKeyPrefix+voucherIdStr).Result()
-	if err != nil {
+	// Lua脚本原子地判断库存和重复下单并扣减库存（避免并发问题）
+	result, err := seckillFromCache(c, voucherIdStr, userId)
+	if err != nil || result == seckillNotLoaded {
 		response.Error(c, response.ErrValidation, "网络繁忙，请重试")
 		return
 	}
-	if remainStock < 0 {
+	if result == seckillSoldOut {
+		metrics.Seckill(metrics.SeckillSoldOut)
 		response.Error(c, response.ErrValidation, "优惠券已经没啦，下次再快一点")
 		return
 	}
+	if result == seckillDuplicate {
+		metrics.Seckill(metrics.SeckillDuplicate)
+		response.Error(c, response.ErrValidation, "每个用户限购一张该优惠券")
+		return
+	}
 	// 3. 预扣减成功后，再执行数据库事务（这一步才走到数据库）
 	q := query.Use(db.DBEngine)
-	globalId := generateOrderId("order")
+	globalId := generateOrderId(c, "order")
+	// 单独记录等锁的时间，慢请求时可以区分是锁、Redis还是MySQL的问题
+	_, lockSpan := tracing.Start(c, "seckill.userLock")
 	UserLockMap.Lock(int(userId))
+	lockSpan.End()
 	err = q.Transaction(func(tx *query.Query) error {
-		voucher, err := getSeckillVoucherById(tx, int64(voucherIdInt))
+		voucher, err := getSeckillVoucherById(c, tx, int64(voucherIdInt))
 		// 3.1 通过CAS乐观锁修改库存
-		RowsAffected, err := UpdateSeckillVoucher(tx, voucher)
+		RowsAffected, err := UpdateSeckillVoucher(c, tx, voucher)
 		// SQL 更新返回的 RowsAffected = 0说明 库存数没同步或者库存数被其他线程扣到0了
 		if RowsAffected == 0 || err != nil {
 			response.Error(c, response.ErrValidation, "你的优惠券被其他人抢走啦，请重试！")
 			// 回滚
-			db.RedisDb.Incr(context.Background(), CacheKey)
+			rollbackSeckillCache(c, voucherIdStr, userId)
+			metrics.Seckill(metrics.SeckillRollback)
 			c.Abort() // 终止请求，不再执行后续代码
 			return err
 		}
@@ -135,19 +138,21 @@ func SeckillVouchers(c *gin.Context) {
 			RefundTime: reqTime,
 			UpdateTime: reqTime,
 		}
-		SeckillVoucherAdd(tx, sv)
-		return err
+		// 插入失败时返回错误，事务回滚，由下面的回滚逻辑恢复Redis里的库存
+		return SeckillVoucherAdd(c, tx, sv)
 	})
 	UserLockMap.Unlock(int(userId))
 	if err != nil {
 		if !c.IsAborted() {
 			//回滚Redis里的库存
-			db.RedisDb.Incr(context.Background(), CacheKey)
+			rollbackSeckillCache(c, voucherIdStr, userId)
+			metrics.Seckill(metrics.SeckillRollback)
 			response.Error(c, response.ErrDatabase, "秒杀事务异常")
 			c.Abort()
 		}
 	}
 	if !c.IsAborted() {
+		metrics.Seckill(metrics.SeckillSuccess)
 		response.Success(c, gin.H{"orderId": strconv.FormatInt(globalId, 10)})
 	}
 }
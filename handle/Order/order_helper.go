@@ -3,28 +3,31 @@ package Order
 import (
 	"context"
 	"strconv"
+	"xzdp/config"
 	"xzdp/dal/model"
 	"xzdp/dal/query"
 	"xzdp/db"
+	"xzdp/pkg/metrics"
 
 	"github.com/bytedance/sonic"
+	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 )
 
 // 根据秒杀优惠券id获取秒杀优惠券信息
-func getSeckillVoucherById(tx *query.Query, id int64) (*model.TbSeckillVoucher, error) {
-	return tx.TbSeckillVoucher.Where(tx.TbSeckillVoucher.VoucherID.Eq(uint64(id))).First()
+func getSeckillVoucherById(ctx context.Context, tx *query.Query, id int64) (*model.TbSeckillVoucher, error) {
+	return tx.TbSeckillVoucher.WithContext(ctx).Where(tx.TbSeckillVoucher.VoucherID.Eq(uint64(id))).First()
 }
 
 // 往秒杀记录表插入数据
-func SeckillVoucherAdd(tx *query.Query, s model.TbVoucherOrder) error {
-	return tx.TbVoucherOrder.Create(&s)
+func SeckillVoucherAdd(ctx context.Context, tx *query.Query, s model.TbVoucherOrder) error {
+	return tx.TbVoucherOrder.WithContext(ctx).Create(&s)
 }
 
 // 修改秒杀优惠券表
-func UpdateSeckillVoucher(tx *query.Query, voucher *model.TbSeckillVoucher) (int64, error) {
+func UpdateSeckillVoucher(ctx context.Context, tx *query.Query, voucher *model.TbSeckillVoucher) (int64, error) {
 	voucher_id, err := strconv.ParseInt(strconv.FormatUint(voucher.VoucherID, 10), 10, 64)
-	result, err := tx.TbSeckillVoucher.
+	result, err := tx.TbSeckillVoucher.WithContext(ctx).
 		Where(
 			tx.TbSeckillVoucher.VoucherID.Eq(uint64(voucher_id)),
 			tx.TbSeckillVoucher.Stock.Eq(voucher.Stock), // 条件：voucher_id = ?
@@ -34,15 +37,74 @@ func UpdateSeckillVoucher(tx *query.Query, voucher *model.TbSeckillVoucher) (int
 	return result.RowsAffected, err
 }
 
+// 同一张优惠券的库存和已下单用户使用相同的hash tag {voucherId}，集群模式下在同一个slot，脚本才能同时操作这两个key
+func seckillStockKey(voucherId string) string {
+	return SeckillVoucherKeyPrefix + "{" + voucherId + "}:stock"
+}
+
+func seckillOrdersKey(voucherId string) string {
+	return SeckillVoucherKeyPrefix + "{" + voucherId + "}:orders"
+}
+
+// 秒杀结果，0为成功
+const (
+	seckillSoldOut   = 1
+	seckillDuplicate = 2
+	seckillNotLoaded = -1
+)
+
+// KEYS[1] 库存 KEYS[2] 已下单的用户（Set）
+// ARGV[1] 用户id ARGV[2] 已下单用户的有效期（毫秒），0表示不过期
+// 判断库存和重复下单、扣减库存、记录用户在一个脚本中完成，返回值见上面的秒杀结果
+var seckillScript = redis.NewScript(`
+local stock = redis.call('GET', KEYS[1])
+if not stock then
+    return -1
+end
+if tonumber(stock) <= 0 then
+    return 1
+end
+if redis.call('SISMEMBER', KEYS[2], ARGV[1]) == 1 then
+    return 2
+end
+redis.call('DECR', KEYS[1])
+redis.call('SADD', KEYS[2], ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+    redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+return 0
+`)
+
+// 下单失败时归还库存，库存已经过期时不归还，下次从数据库重新加载
+var seckillRollbackScript = redis.NewScript(`
+if redis.call('SREM', KEYS[2], ARGV[1]) == 1 and redis.call('EXISTS', KEYS[1]) == 1 then
+    redis.call('INCR', KEYS[1])
+end
+return 0
+`)
+
+// Redis预扣库存
+func seckillFromCache(ctx context.Context, voucherId string, userId int64) (int64, error) {
+	keys := []string{seckillStockKey(voucherId), seckillOrdersKey(voucherId)}
+	return seckillScript.Run(ctx, db.RedisDb, keys, userId, config.Current().Cache.SeckillStock.Milliseconds()).Int64()
+}
+
+// 归还Redis中预扣的库存
+func rollbackSeckillCache(ctx context.Context, voucherId string, userId int64) error {
+	keys := []string{seckillStockKey(voucherId), seckillOrdersKey(voucherId)}
+	return seckillRollbackScript.Run(ctx, db.RedisDb, keys, userId).Err()
+}
+
 // Redis初始化库存
-func SetSeckillStockToCache(CacheKey string, stock int) error {
-	_, err := db.RedisDb.Set(context.Background(), CacheKey, stock, SeckillVoucherTTL).Result()
+func SetSeckillStockToCache(ctx context.Context, CacheKey string, stock int) error {
+	_, err := db.RedisDb.Set(ctx, CacheKey, stock, config.Current().Cache.SeckillStock).Result()
 	return err
 }
 
 // 从redis获取库存
-func GetStockfromCache(CacheKey string) int {
-	res, err := db.RedisDb.Get(context.Background(), CacheKey).Result()
+func GetStockfromCache(ctx context.Context, CacheKey string) int {
+	res, err := db.RedisDb.Get(ctx, CacheKey).Result()
+	metrics.CacheLookup(SeckillVoucherKeyPrefix, err == nil)
 	var stock int
 	if err != nil {
 		return -1
@@ -1,11 +1,9 @@
 package Shop
 
 import (
-	"context"
 	"errors"
 	"log/slog"
 	"strconv"
-	"time"
 	"xzdp/dal/query"
 	"xzdp/db"
 	"xzdp/pkg/response"
@@ -15,14 +13,9 @@ import (
 )
 
 const (
-	shopKeyPrefix    = "cache:shop"
-	shopTypeKey      = ":shopType"
-	BlogPrefix       = "blog"
-	HotBlogKey       = ":hotBlog"
-	shopCacheTTL     = 3 * time.Minute
-	shopTypeCacheTTL = 0 * time.Minute
-	HotBlogTTL       = 5 * time.Minute
-	ShopPageSize     = 10
+	shopKeyPrefix = "cache:shop"
+	shopTypeKey   = ":shopType"
+	ShopPageSize  = 10
 )
 
 func QueryShopById(c *gin.Context) {
@@ -37,18 +30,18 @@ func QueryShopById(c *gin.Context) {
 	}
 	CacheKey := shopKeyPrefix + ":Id:" + idStr
 	//2.从缓存中查找
-	shop, err := getShopByIdFromCache(CacheKey)
+	shop, err := getShopByIdFromCache(c, CacheKey)
 	//3.缓存未命中或者出错，从数据库中查找
 	if shop == nil || err != nil {
-		slog.Error("根据商户id查询cache未命中，开始从数据库中查找")
+		slog.ErrorContext(c, "根据商户id查询cache未命中，开始从数据库中查找")
 	}
-	shop, err = getShopByIdFromDB(idInt)
+	shop, err = getShopByIdFromDB(c, idInt)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		response.Error(c, response.ErrNotFound, "商户表中查询结果为空")
 		return
 	}
 	//4.将数据写入缓存（可异步）并返回结果
-	err = setShopByIdtoCache(CacheKey, *shop)
+	err = setShopByIdtoCache(c, CacheKey, *shop)
 	if err != nil {
 		return
 	}
@@ -57,7 +50,7 @@ func QueryShopById(c *gin.Context) {
 
 func QueryShopTypeList(context *gin.Context) {
 	//1.从缓存中查找
-	types, err := getShopTypeListFromCache()
+	types, err := getShopTypeListFromCache(context)
 	//2.缓存命中，直接返回
 	if types != nil && err == nil {
 		response.Success(context, types)
@@ -65,40 +58,17 @@ func QueryShopTypeList(context *gin.Context) {
 	}
 
 	//3.缓存未命中或者出错，从数据库中查找
-	shopTypeList, err := getShopTypeListFromDB()
+	shopTypeList, err := getShopTypeListFromDB(context)
 	if shopTypeList == nil || err != nil {
 		response.Error(context, response.ErrDatabaseNotFind, "查询商户类型失败")
 		return
 	}
 
 	//4.将数据写入缓存（可异步）并返回结果
-	setShopTypeListToCache(shopTypeList)
+	setShopTypeListToCache(context, shopTypeList)
 	response.Success(context, shopTypeList)
 }
 
-func GetHotBlog(c *gin.Context) {
-	//1.从上下文中拿到页码（路径参数用Query方法获取）
-	pageNum := c.Query("current")
-	//2.带着页码去缓存查询
-	cacheRes, err := getBlogByPageNumFromCache(pageNum)
-	if cacheRes != nil && err == nil {
-		response.Success(c, cacheRes)
-		return
-	}
-	//3.缓存未命中，从数据库查询
-	dbRes, err := getBlogByPageNumFromDB(pageNum)
-	if err != nil {
-		response.Error(c, response.ErrDatabase, "查询db失败")
-		return
-	}
-	err = setHotBlogToCache(dbRes)
-	if err != nil {
-		slog.Log(c, 1, "博客缓存失败")
-	}
-	response.Success(c, dbRes)
-
-}
-
 func GetShopByTypeId(c *gin.Context) {
 	//1.解析参数并验证
 	typeId, current, x, y, sortBy := c.Query("typeId"), c.Query("current"), c.Query("x"), c.Query("y"), c.Query("sortBy")
@@ -121,25 +91,25 @@ func GetShopByTypeId(c *gin.Context) {
 	}
 	// 3.从缓存中查找
 	CacheKey := shopKeyPrefix + ":typeId:" + typeId + ":sortBy:" + sortBy + ":current:" + current
-	cacheRes, err := getShopsByTypeIdFromCache(CacheKey)
+	cacheRes, err := getShopsByTypeIdFromCache(c, CacheKey)
 	if cacheRes != nil && err == nil {
 		response.Success(c, cacheRes)
 		return
 	}
 
 	// 4.缓存未命中，从数据库查询
-	dbRes, err := getShopsByTypeIdFromDB(typeIdInt, sortBy, currentInt)
+	dbRes, err := getShopsByTypeIdFromDB(c, typeIdInt, sortBy, currentInt)
 	if err != nil {
-		slog.Error("数据库查询失败", "typeIdInt", typeIdInt, "err", err)
+		slog.ErrorContext(c, "数据库查询失败", "typeIdInt", typeIdInt, "err", err)
 		response.Error(c, response.ErrDatabaseNotFind)
 		return
 	}
 
 	// 5.只有当查询结果不为空时才写入缓存，避免缓存空数组导致重复渲染
 	if len(dbRes) > 0 {
-		err = setShopsByTypeIdToCache(CacheKey, dbRes)
+		err = setShopsByTypeIdToCache(c, CacheKey, dbRes)
 		if err != nil {
-			slog.Error("写入缓存失败", "CacheKey", CacheKey, "err", err)
+			slog.ErrorContext(c, "写入缓存失败", "CacheKey", CacheKey, "err", err)
 			// 缓存失败不影响返回结果，继续执行
 		}
 		response.Success(c, dbRes)
@@ -153,7 +123,7 @@ func UpdateShop(c *gin.Context) {
 	var shop ShopRequest
 	err := c.ShouldBindJSON(&shop)
 	if err != nil {
-		slog.Error("bindjson bad", "err", err)
+		slog.ErrorContext(c, "bindjson bad", "err", err)
 		response.Error(c, response.ErrBind)
 		return
 	}
@@ -172,16 +142,16 @@ func update(c *gin.Context, shop *ShopRequest) {
 	//若想确保指定字段被更新,应使用Select更新选定字段，或使用map来完成更新
 	data := shop.ToModel()
 	tbshop := query.TbShop
-	_, err := tbshop.Where(tbshop.ID.Eq(shop.ID)).Updates(data)
+	_, err := tbshop.WithContext(c).Where(tbshop.ID.Eq(shop.ID)).Updates(data)
 	if err != nil {
-		slog.Error("update mysql bad", "err", err)
+		slog.ErrorContext(c, "update mysql bad", "err", err)
 		response.Error(c, response.ErrDatabase)
 		return
 	}
 
 	//2.删除缓存
 	key := shopKeyPrefix + strconv.Itoa(int(shop.ID))
-	db.RedisDb.Del(context.Background(), key)
+	db.RedisDb.Del(c, key)
 
 	response.Success(c, nil)
 }
@@ -192,7 +162,7 @@ func AddShop(c *gin.Context) {
 	var shop ShopRequest
 	err := c.ShouldBindJSON(&shop)
 	if err != nil {
-		slog.Error("bindjson bad", "err", err)
+		slog.ErrorContext(c, "bindjson bad", "err", err)
 		response.Error(c, response.ErrBind)
 		return
 	}
@@ -201,9 +171,9 @@ func AddShop(c *gin.Context) {
 	shop.ID = 0
 
 	data := shop.ToModel()
-	err = query.TbShop.Create(data)
+	err = query.TbShop.WithContext(c).Create(data)
 	if err != nil {
-		slog.Error("mysql create shop err", "err", err)
+		slog.ErrorContext(c, "mysql create shop err", "err", err)
 		response.Error(c, response.ErrDatabase)
 		return
 	}
@@ -226,7 +196,7 @@ func DelShop(c *gin.Context) {
 		return
 	}
 	shop := query.TbShop
-	result, err := shop.Where(shop.ID.Eq(uint64(val))).Delete()
+	result, err := shop.WithContext(c).Where(shop.ID.Eq(uint64(val))).Delete()
 	if err != nil {
 		response.Error(c, response.ErrDatabase)
 	}
@@ -236,9 +206,9 @@ func DelShop(c *gin.Context) {
 	}
 	//删除缓存
 	key := "cache:shop:type:sortBy:empty:current:1"
-	_, err = db.RedisDb.Del(context.Background(), key).Result()
+	_, err = db.RedisDb.Del(c, key).Result()
 	if err != nil {
-		slog.Error("redis delete shop error", "error", err)
+		slog.ErrorContext(c, "redis delete shop error", "error", err)
 	}
 
 	response.Success(c, nil)
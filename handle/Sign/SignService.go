@@ -0,0 +1,123 @@
+package Sign
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+	"xzdp/config"
+	"xzdp/handle/Credit"
+	"xzdp/middleware"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	signKeyPrefix   = "sign:bitmap:"
+	backupKeyPrefix = "sign:backup:"
+	monthLayout     = "200601"
+	dateLayout      = "2006-01-02"
+	// 归档到tb_sign之后bitmap还要保留一段时间，用来计算跨月的连续签到
+	signKeyTTL = 400 * 24 * time.Hour
+)
+
+var errQuotaExceeded = errors.New("backup quota exceeded")
+
+// POST /api/sign
+func SignIn(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	//1.把今天对应的位设为1
+	signed, err := setSignBit(c, userId, time.Now())
+	if err != nil {
+		slog.ErrorContext(c, "签到失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase, "签到失败")
+		return
+	}
+	if signed {
+		response.Error(c, response.ErrAlreadySigned)
+		return
+	}
+	//2.计算连续签到天数并发放积分
+	streak, err := getStreak(c, userId, time.Now())
+	if err != nil {
+		slog.ErrorContext(c, "计算连续签到失败", "userId", userId, "err", err)
+	}
+	Credit.AwardSign(c, userId, streak)
+	response.Success(c, gin.H{"streak": streak})
+}
+
+// POST /api/sign/backup
+func BackupSign(c *gin.Context) {
+	var req backupSignReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	//1.只能补签本月今天之前的日期
+	now := time.Now()
+	day, err := time.ParseInLocation(dateLayout, req.Date, now.Location())
+	if err != nil {
+		response.Error(c, response.ErrValidation, "日期格式错误，应为2006-01-02")
+		return
+	}
+	if day.Year() != now.Year() || day.Month() != now.Month() || day.Day() >= now.Day() {
+		response.Error(c, response.ErrValidation, "只能补签本月今天之前的日期")
+		return
+	}
+	//2.检查配额并补签
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	signed, err := setBackupSign(c, userId, day, config.Current().Sign.BackupQuota)
+	if errors.Is(err, errQuotaExceeded) {
+		response.Error(c, response.ErrBackupQuotaExceeded)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "补签失败", "userId", userId, "date", req.Date, "err", err)
+		response.Error(c, response.ErrDatabase, "补签失败")
+		return
+	}
+	if signed {
+		response.Error(c, response.ErrAlreadySigned, "这一天已经签到过了")
+		return
+	}
+	streak, err := getStreak(c, userId, now)
+	if err != nil {
+		slog.ErrorContext(c, "计算连续签到失败", "userId", userId, "err", err)
+	}
+	//补签只奖励基础积分，连续签到的额外奖励只在当天签到时发放
+	Credit.AwardBackupSign(c, userId)
+	response.Success(c, gin.H{"streak": streak})
+}
+
+// GET /api/sign/stat
+func GetSignStat(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	now := time.Now()
+	//1.一次取出本月到今天为止的签到记录
+	v, err := getMonthBits(c, userId, now, now.Day())
+	if err != nil {
+		slog.ErrorContext(c, "查询签到记录失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	//2.连续签到天数可能跨月，单独计算
+	streak, err := getStreak(c, userId, now)
+	if err != nil {
+		slog.ErrorContext(c, "计算连续签到失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	backupCount, err := getBackupCount(c, userId, now)
+	if err != nil {
+		slog.ErrorContext(c, "查询补签次数失败", "userId", userId, "err", err)
+	}
+	days := signedDays(v, now.Day())
+	response.Success(c, signStatResponse{
+		Month:      now.Format("2006-01"),
+		Streak:     streak,
+		MonthCount: len(days),
+		SignedDays: days,
+		BackupLeft: max(config.Current().Sign.BackupQuota-backupCount, 0),
+	})
+}
@@ -0,0 +1,118 @@
+package Sign
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+)
+
+// 定时把Redis中的签到bitmap归档到tb_sign，一天一行
+// 每次归档本月和上个月（月初的时候上个月最后几天可能还没归档），已经归档过的日期会跳过，所以可以重复执行
+
+// StartArchiver 启动归档协程，ctx取消时退出，退出后调用wg.Done
+func StartArchiver(ctx context.Context, wg *sync.WaitGroup) {
+	interval := config.Current().Sign.ArchiveInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				now := time.Now()
+				lastMonth := time.Date(now.Year(), now.Month(), 0, 0, 0, 0, 0, now.Location())
+				for _, month := range []time.Time{lastMonth, now} {
+					if err := archiveMonth(ctx, month); err != nil {
+						slog.ErrorContext(ctx, "签到记录归档失败", "month", month.Format(monthLayout), "err", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+func archiveMonth(ctx context.Context, month time.Time) error {
+	// 本月只归档到今天，上个月归档整月
+	days := month.Day()
+	now := time.Now()
+	if month.Year() != now.Year() || month.Month() != now.Month() {
+		days = time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location()).Day()
+	}
+	suffix := ":" + month.Format(monthLayout)
+	return db.ScanKeys(ctx, signKeyPrefix+"*"+suffix, func(key string) error {
+		userId, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(key, signKeyPrefix), suffix), 10, 64)
+		if err != nil {
+			return nil
+		}
+		if err = archiveUserMonth(ctx, userId, month, days); err != nil {
+			slog.ErrorContext(ctx, "归档用户签到记录失败", "userId", userId, "month", month.Format(monthLayout), "err", err)
+		}
+		return nil
+	})
+}
+
+func archiveUserMonth(ctx context.Context, userId int64, month time.Time, days int) error {
+	v, err := getMonthBits(ctx, userId, month, days)
+	if err != nil {
+		return err
+	}
+	backups, err := db.RedisDb.SMembers(ctx, backupKey(userId, month)).Result()
+	if err != nil {
+		return err
+	}
+	backupSet := make(map[int]struct{}, len(backups))
+	for _, d := range backups {
+		if day, err := strconv.Atoi(d); err == nil {
+			backupSet[day] = struct{}{}
+		}
+	}
+	//1.查出这个月已经归档的日期
+	signQuery := query.TbSign
+	archived, err := signQuery.WithContext(ctx).Where(
+		signQuery.UserID.Eq(uint64(userId)),
+		signQuery.Year.Eq(int32(month.Year())),
+		signQuery.Month.Eq(int32(month.Month())),
+	).Find()
+	if err != nil {
+		return err
+	}
+	archivedSet := make(map[int]struct{}, len(archived))
+	for _, s := range archived {
+		archivedSet[s.Date.Day()] = struct{}{}
+	}
+	//2.只插入还没归档的日期
+	var records []*model.TbSign
+	for _, day := range signedDays(v, days) {
+		if _, ok := archivedSet[day]; ok {
+			continue
+		}
+		var isBackup uint32
+		if _, ok := backupSet[day]; ok {
+			isBackup = 1
+		}
+		records = append(records, &model.TbSign{
+			UserID:   uint64(userId),
+			Year:     int32(month.Year()),
+			Month:    int32(month.Month()),
+			Date:     time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, month.Location()),
+			IsBackup: isBackup,
+		})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return signQuery.WithContext(ctx).CreateInBatches(records, 100)
+}
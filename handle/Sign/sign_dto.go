@@ -0,0 +1,15 @@
+package Sign
+
+// 补签请求结构体
+type backupSignReq struct {
+	Date string `json:"date" binding:"required"` // 补签日期，格式 2006-01-02，只能补签本月今天之前的日期
+}
+
+// 签到统计
+type signStatResponse struct {
+	Month      string `json:"month"`      // 2006-01
+	Streak     int    `json:"streak"`     // 截至今天（今天没签就截至昨天）的连续签到天数
+	MonthCount int    `json:"monthCount"` // 本月签到天数
+	SignedDays []int  `json:"signedDays"` // 本月签到的日期
+	BackupLeft int    `json:"backupLeft"` // 本月剩余补签次数
+}
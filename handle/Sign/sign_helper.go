@@ -0,0 +1,137 @@
+package Sign
+
+import (
+	"context"
+	"math/bits"
+	"strconv"
+	"time"
+	"xzdp/db"
+)
+
+// 每个用户每个月一个bitmap：sign:bitmap:{userId}:{yyyyMM}，第day-1位为1表示这天签到了
+// 补签的日期另外记在Set中：sign:backup:{userId}:{yyyyMM}，用来统计补签次数和归档时标记IsBackup
+func signKey(userId int64, month time.Time) string {
+	return signKeyPrefix + strconv.FormatInt(userId, 10) + ":" + month.Format(monthLayout)
+}
+
+func backupKey(userId int64, month time.Time) string {
+	return backupKeyPrefix + strconv.FormatInt(userId, 10) + ":" + month.Format(monthLayout)
+}
+
+// 签到，返回这一天之前是否已经签过
+func setSignBit(ctx context.Context, userId int64, day time.Time) (bool, error) {
+	key := signKey(userId, day)
+	old, err := db.RedisDb.SetBit(ctx, key, int64(day.Day()-1), 1).Result()
+	if err != nil {
+		return false, err
+	}
+	db.RedisDb.Expire(ctx, key, signKeyTTL)
+	return old == 1, nil
+}
+
+// 补签，先把补签日期加入Set再检查数量，超过配额时回滚，避免并发补签超出配额
+func setBackupSign(ctx context.Context, userId int64, day time.Time, quota int) (alreadySigned bool, err error) {
+	signed, err := db.RedisDb.GetBit(ctx, signKey(userId, day), int64(day.Day()-1)).Result()
+	if err != nil {
+		return false, err
+	}
+	if signed == 1 {
+		return true, nil
+	}
+	key := backupKey(userId, day)
+	pipe := db.RedisDb.TxPipeline()
+	pipe.SAdd(ctx, key, day.Day())
+	card := pipe.SCard(ctx, key)
+	pipe.Expire(ctx, key, signKeyTTL)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	if int(card.Val()) > quota {
+		db.RedisDb.SRem(ctx, key, day.Day())
+		return false, errQuotaExceeded
+	}
+	return setSignBit(ctx, userId, day)
+}
+
+// 用BITFIELD GET u{days} 0 一次取出这个月第1天到第days天的签到记录
+// 返回的整数中最高位是第1天，最低位是第days天
+func getMonthBits(ctx context.Context, userId int64, month time.Time, days int) (uint64, error) {
+	res, err := db.RedisDb.BitField(ctx, signKey(userId, month), "GET", "u"+strconv.Itoa(days), 0).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	return uint64(res[0]), nil
+}
+
+// 把bitmap的值转换为签到的日期列表
+func signedDays(v uint64, days int) []int {
+	res := make([]int, 0, bits.OnesCount64(v))
+	for day := 1; day <= days; day++ {
+		if v>>(days-day)&1 == 1 {
+			res = append(res, day)
+		}
+	}
+	return res
+}
+
+// 从最低位（最后一天）开始往前数连续的1
+func trailingOnes(v uint64, days int) int {
+	n := bits.TrailingZeros64(^v)
+	if n > days {
+		n = days
+	}
+	return n
+}
+
+// 计算截至today的连续签到天数，今天还没签到的话从昨天开始算，不会因为今天还没签就断签
+// 连续签到跨月时继续往前一个月的bitmap查找
+func getStreak(ctx context.Context, userId int64, today time.Time) (int, error) {
+	v, err := getMonthBits(ctx, userId, today, today.Day())
+	if err != nil {
+		return 0, err
+	}
+	days := today.Day()
+	if v&1 == 0 {
+		// 今天没签到，从昨天开始算
+		v >>= 1
+		days--
+	}
+	streak := trailingOnes(v, days)
+	// 这个月从第1天起一直连续（或者今天是1号还没签），继续看上个月
+	full := streak == days
+	month := today
+	for full {
+		month = time.Date(month.Year(), month.Month(), 0, 0, 0, 0, 0, month.Location())
+		days = month.Day()
+		v, err = getMonthBits(ctx, userId, month, days)
+		if err != nil {
+			return 0, err
+		}
+		n := trailingOnes(v, days)
+		streak += n
+		full = n == days
+	}
+	return streak, nil
+}
+
+func getBackupCount(ctx context.Context, userId int64, month time.Time) (int, error) {
+	n, err := db.RedisDb.SCard(ctx, backupKey(userId, month)).Result()
+	return int(n), err
+}
+
+// DeleteUserSigns 删除用户在Redis中的签到记录，注销账号时调用，避免之后又被归档到tb_sign
+func DeleteUserSigns(ctx context.Context, userId int64) error {
+	id := strconv.FormatInt(userId, 10)
+	for _, pattern := range []string{signKeyPrefix + id + ":*", backupKeyPrefix + id + ":*"} {
+		err := db.ScanKeys(ctx, pattern, func(key string) error {
+			return db.RedisDb.Del(ctx, key).Err()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
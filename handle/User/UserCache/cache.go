@@ -0,0 +1,21 @@
+package UserCache
+
+import (
+	"context"
+	"strconv"
+	"xzdp/db"
+)
+
+// 用户资料缓存的key，User包读写缓存，积分变动、账号注销等修改了用户资料的地方用DeleteInfo删除缓存
+// 单独放一个包是因为User包依赖Account等包，这些包不能反过来依赖User包
+
+const InfoKeyPrefix = "cache:user:info:"
+
+func InfoKey(userId uint64) string {
+	return InfoKeyPrefix + strconv.FormatUint(userId, 10)
+}
+
+// DeleteInfo 删除用户资料缓存，下次查询时从数据库加载
+func DeleteInfo(ctx context.Context, userId uint64) error {
+	return db.RedisDb.Del(ctx, InfoKey(userId)).Err()
+}
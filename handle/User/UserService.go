@@ -5,14 +5,19 @@ import (
 	"errors"
 	"log/slog"
 	"math/rand"
+	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"xzdp/config"
 	"xzdp/dal/model"
 	"xzdp/dal/query"
 	"xzdp/db"
+	"xzdp/handle/Account"
 	"xzdp/middleware"
 	"xzdp/pkg/response"
+	"xzdp/pkg/sms"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -32,19 +37,53 @@ type userResponse struct {
 	Icon     string `json:"icon"`
 }
 
+// 刷新令牌请求结构体
+type refreshTokenReq struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// 设置/修改密码请求结构体，还没有设置过密码时不需要旧密码
+type changePasswordReq struct {
+	OldPassword string `json:"oldPassword" binding:"omitempty,min=6,max=20"`
+	NewPassword string `json:"newPassword" binding:"required,min=6,max=20"`
+}
+
+// 通过验证码重置密码请求结构体
+type resetPasswordReq struct {
+	Phone       string `json:"phone" binding:"required"`
+	Code        string `json:"code" binding:"required,min=4,max=6"`
+	NewPassword string `json:"newPassword" binding:"required,min=6,max=20"`
+}
+
 // 修改昵称请求结构体
 type updateNickNameReq struct {
 	NickName string `json:"nickName" binding:"required,min=1,max=15"` // 昵称，1-20个字符
 }
 
+var errInvalidBirthday = response.NewBusinessError(response.ErrValidation, "生日必须是2006-01-02格式的日期，并且不能晚于今天")
+
+// 头像保存在静态资源目录下，通过 /imgs/icons/ 访问
+var allowedIconExt = map[string]struct{}{".jpg": {}, ".jpeg": {}, ".png": {}, ".gif": {}}
+
+const (
+	iconDir       = "nginx-1.18.0/html/hmdp/imgs/icons"
+	iconURLPrefix = "/imgs/icons/"
+	maxIconSize   = 2 << 20
+)
+
 const (
-	userPrefix       = "cache:user"
-	phoneKeyPrefix   = ":phone"
-	inforKeyPrefix   = ":info"
-	codeExpiration   = 3 * time.Minute
-	userInfoCacheTTL = 10 * time.Minute
+	userPrefix     = "cache:user"
+	phoneKeyPrefix = ":phone"
+	codeExpiration = 3 * time.Minute
+	// 验证码短信模板，在配置文件SMS.Templates中
+	smsTemplateVerifyCode = "verifyCode"
 )
 
+// 验证码的key：cache:user:phone:{phone}，手机号作为hash tag，输错次数的key与它在集群模式下位于同一个slot
+func verifyCodeKey(phone string) string {
+	return userPrefix + phoneKeyPrefix + ":{" + phone + "}"
+}
+
 var phoneRe = regexp.MustCompile(`^1[3-9]\d{9}$`)
 
 func isValidPhone(phone string) bool {
@@ -63,11 +102,38 @@ func SendVerifyCode(c *gin.Context) {
 		response.Error(c, response.ErrValidation, "手机号格式有误！")
 		return
 	}
-	//2.发送验证码，一手一码（典型键值对，还有过期时间-->存在redis中）
-	rand.Seed(time.Now().UnixNano())
-	code := 1000 + rand.Intn(9000)
-	db.RedisDb.SetNX(c, userPrefix+phoneKeyPrefix+":"+phoneNum, code, codeExpiration)
-	response.Success(c, gin.H{"code": code})
+	//2.防刷检查，不通过时已经返回了错误
+	if !allowSendCode(c, phoneNum) {
+		return
+	}
+	//3.生成验证码，一手一码（典型键值对，还有过期时间-->存在redis中），重新发送时覆盖旧的验证码并清空输错次数
+	code := strconv.Itoa(1000 + rand.Intn(9000))
+	key := verifyCodeKey(phoneNum)
+	pipe := db.RedisDb.TxPipeline()
+	pipe.Set(c, key, code, codeExpiration)
+	pipe.Del(c, key+codeAttemptsSuffix)
+	_, err := pipe.Exec(c)
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "发送验证码失败"))
+		return
+	}
+	//4.发送短信，发送失败时删除验证码
+	err = sms.Send(c, phoneNum, smsTemplateVerifyCode, map[string]string{
+		"code":    code,
+		"minutes": strconv.Itoa(int(codeExpiration / time.Minute)),
+	})
+	if err != nil {
+		db.RedisDb.Del(c, key)
+		slog.ErrorContext(c, "发送验证码短信失败", "phone", MaskPhoneNumber(phoneNum), "err", err)
+		response.Error(c, response.ErrUnknown, "发送验证码失败，请稍后再试")
+		return
+	}
+	//5.只有开发环境才把验证码返回给前端，方便调试
+	if config.ServerOption.RunMode == gin.DebugMode {
+		response.Success(c, gin.H{"code": code})
+		return
+	}
+	response.Success(c, gin.H{"message": "验证码已发送"})
 }
 
 func Login(c *gin.Context) {
@@ -82,22 +148,45 @@ func Login(c *gin.Context) {
 		response.Error(c, response.ErrValidation, "手机号格式有误！")
 		return
 	}
-	//2.从redis拿验证码进行校验
+	//2.防刷检查，不通过时已经返回了错误
+	if !allowLogin(c, loginRequest.Phone) {
+		return
+	}
+	//3.填了密码时走密码登录，否则从redis拿验证码进行校验
 	var user *model.TbUser
-	user, e := CodeLogin(loginRequest)
+	var e error
+	if loginRequest.Password != "" {
+		user, e = PasswordLogin(c, loginRequest)
+	} else {
+		user, e = CodeLogin(c, loginRequest)
+	}
 	if user == nil || e != nil {
 		response.HandleBusinessError(c, e)
 		return
 	}
-	//3. 生成Token，需要用到手机号 + userId
-	Token, err := middleware.GenerateToken(loginRequest.Phone, int64(user.ID))
+	//4.创建会话并返回令牌
+	RespondLogin(c, user)
+}
+
+// RespondLogin 登录成功后创建会话、签发令牌并返回用户信息，验证码登录、密码登录和第三方登录共用
+func RespondLogin(c *gin.Context, user *model.TbUser) {
+	//1. 冷静期内重新登录，撤销注销申请
+	canceled, err := Account.CancelDeletion(c, user.ID)
+	if err != nil {
+		slog.ErrorContext(c, "撤销注销申请失败", "userId", user.ID, "err", err)
+	} else if canceled {
+		slog.InfoContext(c, "重新登录，撤销注销申请", "userId", user.ID)
+	}
+	//2. 创建会话并生成Token，令牌中只有userId，不包含手机号
+	tokens, err := middleware.IssueTokens(c, int64(user.ID), middleware.NewSessionDevice(c))
 	if err != nil {
-		slog.Error("生成Token失败", "err", err)
+		slog.ErrorContext(c, "生成Token失败", "err", err)
 		response.Error(c, response.ErrorLoginFaild, "")
 		return
 	}
 	response.Success(c, gin.H{
-		"token": Token,
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
 		"user": userResponse{ //返回给前端展示或者缓存的用户信息
 			ID:       user.ID,
 			Phone:    user.Phone,
@@ -107,25 +196,54 @@ func Login(c *gin.Context) {
 	})
 }
 
-func CodeLogin(loginReqstruct loginReqstruct) (*model.TbUser, error) {
-	// 1.去缓存找对应的验证码
-	DbCode, err := db.RedisDb.Get(context.Background(), userPrefix+phoneKeyPrefix+":"+loginReqstruct.Phone).Result()
+// LoginWithCode 校验手机号和验证码，通过后返回用户（新手机号自动注册），不通过时已经写好错误响应并返回false
+func LoginWithCode(c *gin.Context, phone, code string) (*model.TbUser, bool) {
+	if !isValidPhone(phone) {
+		response.Error(c, response.ErrValidation, "手机号格式有误！")
+		return nil, false
+	}
+	if !allowLogin(c, phone) {
+		return nil, false
+	}
+	user, err := CodeLogin(c, loginReqstruct{Phone: phone, Code: code})
+	if err != nil {
+		response.HandleBusinessError(c, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// 校验验证码，正确后删除，保证一个验证码只能用一次
+func checkVerifyCode(ctx context.Context, phone, code string) error {
+	key := verifyCodeKey(phone)
+	DbCode, err := db.RedisDb.Get(ctx, key).Result()
 	if DbCode == "" || err != nil {
-		return nil, response.NewBusinessError(response.ErrExpired, "验证码不存在或已过期")
+		return response.NewBusinessError(response.ErrExpired, "验证码不存在或已过期")
+	}
+	if code != DbCode {
+		return recordCodeFailure(ctx, phone)
+	}
+	//删除失败时验证码还能再用，不能放行
+	if err = db.RedisDb.Del(ctx, key, key+codeAttemptsSuffix).Err(); err != nil {
+		return response.WrapBusinessError(response.ErrDatabase, err, "校验验证码失败")
 	}
-	if loginReqstruct.Code != DbCode {
-		return nil, response.NewBusinessError(response.ErrPasswordIncorrect, "验证码错误")
+	return nil
+}
+
+func CodeLogin(ctx context.Context, loginReqstruct loginReqstruct) (*model.TbUser, error) {
+	// 1.校验验证码，正确时删除Redis中的验证码
+	err := checkVerifyCode(ctx, loginReqstruct.Phone, loginReqstruct.Code)
+	if err != nil {
+		return nil, err
 	}
-	//2. 验证码正确，删除Redis中的验证码，并生成Token
-	db.RedisDb.Del(context.Background(), userPrefix+phoneKeyPrefix+":"+loginReqstruct.Phone)
-	//3.判断是否是新用户，是则新建帐号
+	//2.判断是否是新用户，是则新建帐号
 	userQuery := query.TbUser
-	user, err := userQuery.Where(userQuery.Phone.Eq(loginReqstruct.Phone)).First()
+	user, err := userQuery.WithContext(ctx).Where(userQuery.Phone.Eq(loginReqstruct.Phone)).First()
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 用户不存在，创建新用户
 			newUser := &model.TbUser{Phone: loginReqstruct.Phone, NickName: MaskPhoneNumber(loginReqstruct.Phone)}
-			err = userQuery.Create(newUser)
+			err = userQuery.WithContext(ctx).Create(newUser)
 			if err != nil {
 				return nil, response.WrapBusinessError(response.ErrDatabase, err, "")
 			}
@@ -141,55 +259,104 @@ func CodeLogin(loginReqstruct loginReqstruct) (*model.TbUser, error) {
 func GetUserInfo(c *gin.Context) {
 	//1. 从上下文获取用户信息
 	userId := c.GetInt64(middleware.CtxKeyUserId)
-	//2. 从Cache获取用户信息
-	user, err := getUserByIdFromCache(strconv.FormatInt(userId, 10))
-	//3. 没有就去DB找并写回Cache再返回
-	if user == nil || err != nil {
-		user, err = getUserByIdFromDb(userId)
-		if err != nil {
-			response.HandleBusinessError(c, err)
-			return
-		}
-		err = setUserToCache(user)
-		if err != nil {
-			// 缓存设置失败不影响返回，只记录日志
-			slog.Error("设置用户缓存失败", "err", err)
-		}
+	//2. 先查Cache，没有就去DB找并写回Cache
+	profile, err := loadProfile(c, userId)
+	if err != nil {
+		response.HandleBusinessError(c, err)
+		return
 	}
-	//4. 转换为响应格式（使用驼峰命名，匹配前端）
+	//3. 转换为响应格式（使用驼峰命名，匹配前端）
 	response.Success(c, userResponse{
-		ID:       user.ID,
-		Phone:    user.Phone,
-		NickName: user.NickName,
-		Icon:     user.Icon,
+		ID:       profile.ID,
+		Phone:    profile.Phone,
+		NickName: profile.NickName,
+		Icon:     profile.Icon,
 	})
 }
 
+// GET /api/user/info/:userId 返回完整资料，查看别人的资料时手机号脱敏
 func GetUserInfoById(c *gin.Context) {
 	userId := c.Param("userId")
 	id, err := strconv.ParseInt(userId, 10, 64)
 	if err != nil {
-		response.HandleBusinessError(c, err) // 假设有定义
+		response.Error(c, response.ErrValidation, "无效的用户id")
 		return
 	}
-	user, err := getUserByIdFromCache(strconv.FormatInt(id, 10))
-	if user == nil || err != nil {
-		user, err = getUserByIdFromDb(id)
-		if err != nil {
-			response.HandleBusinessError(c, err)
-			return
-		}
-		err = setUserToCache(user)
-		if err != nil {
-			slog.Error("设置用户缓存失败", "err", err)
-		}
+	profile, err := loadProfile(c, id)
+	if err != nil {
+		response.HandleBusinessError(c, err)
+		return
 	}
-	response.Success(c, userResponse{
-		ID:       user.ID,
-		Phone:    user.Phone,
-		NickName: user.NickName,
-		Icon:     user.Icon,
-	})
+	if id != c.GetInt64(middleware.CtxKeyUserId) {
+		// 缓存里的是共享对象的副本，修改不会影响缓存
+		masked := *profile
+		masked.Phone = MaskPhoneNumber(profile.Phone)
+		profile = &masked
+	}
+	response.Success(c, profile)
+}
+
+// PUT /api/user/info
+func EditProfile(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	var req updateProfileReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	columns, err := req.toColumns()
+	if err != nil {
+		response.HandleBusinessError(c, err)
+		return
+	}
+	if len(columns) == 0 {
+		response.Error(c, response.ErrValidation, "没有需要修改的资料")
+		return
+	}
+	//1.更新数据库再删除缓存
+	err = updateUserInfoColumns(c, uint64(userId), columns)
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "修改资料失败"))
+		return
+	}
+	deleteUserInfoFromCache(c, strconv.FormatInt(userId, 10))
+	response.Success(c, gin.H{"message": "资料修改成功"})
+}
+
+// POST /api/user/icon 表单字段file，上传头像
+func UploadIcon(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请选择要上传的头像")
+		return
+	}
+	//1.校验大小和格式
+	if file.Size > maxIconSize {
+		response.Error(c, response.ErrValidation, "头像不能超过2MB")
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if _, ok := allowedIconExt[ext]; !ok {
+		response.Error(c, response.ErrValidation, "头像只支持jpg、jpeg、png、gif格式")
+		return
+	}
+	//2.保存到静态资源目录，文件名带上用户id和时间戳避免重名
+	fileName := strconv.FormatInt(userId, 10) + "_" + strconv.FormatInt(time.Now().UnixNano(), 10) + ext
+	if err = c.SaveUploadedFile(file, filepath.Join(iconDir, fileName)); err != nil {
+		slog.ErrorContext(c, "保存头像失败", "userId", userId, "err", err)
+		response.Error(c, response.ErrUnknown, "上传头像失败")
+		return
+	}
+	//3.更新tb_user.icon并删除缓存
+	icon := iconURLPrefix + fileName
+	if err = updateUserIcon(c, uint64(userId), icon); err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "修改头像失败"))
+		return
+	}
+	deleteUserInfoFromCache(c, strconv.FormatInt(userId, 10))
+	response.Success(c, gin.H{"icon": icon})
 }
 
 func EditNickname(c *gin.Context) {
@@ -209,29 +376,167 @@ func EditNickname(c *gin.Context) {
 		return
 	}
 
-	//4. 更新数据库
+	//4. 更新数据库，只更新非零字段，不需要带上手机号
 	user := &model.TbUser{
 		ID:       uint64(userId),
 		NickName: req.NickName,
-		Phone:    c.GetString(middleware.CtxKeyUserPhone),
 	}
-	err = UpdateUserInfoById(user)
+	err = UpdateUserInfoById(c, user)
 	if err != nil {
 		response.HandleBusinessError(c, err)
 		return
 	}
-	deleteUserInfoFromCache(strconv.FormatInt(userId, 10))
+	deleteUserInfoFromCache(c, strconv.FormatInt(userId, 10))
 	response.Success(c, gin.H{"message": "昵称修改成功"})
 }
 
+// 退出当前设备：当前令牌立即失效，refresh token也不能再使用
 func Logout(c *gin.Context) {
 	//1.拿到唯一标识
 	userId := c.GetInt64(middleware.CtxKeyUserId)
-	//2.删除缓存
-	err := deleteUserInfoFromCache(strconv.FormatInt(userId, 10))
+	//2.注销当前会话
+	err := middleware.RevokeSession(c, middleware.GetClaims(c))
 	if err != nil {
-		response.HandleBusinessError(c, err)
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "退出失败"))
 		return
 	}
+	//3.删除缓存
+	deleteUserInfoFromCache(c, strconv.FormatInt(userId, 10))
 	response.Success(c, gin.H{"message": "退出成功"})
 }
+
+// 退出所有设备
+func LogoutAll(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	err := middleware.RevokeAllSessions(c, userId)
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "退出失败"))
+		return
+	}
+	deleteUserInfoFromCache(c, strconv.FormatInt(userId, 10))
+	response.Success(c, gin.H{"message": "已退出所有设备"})
+}
+
+// POST /api/user/refresh 用refresh token换新的令牌
+func RefreshToken(c *gin.Context) {
+	var req refreshTokenReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	tokens, err := middleware.RefreshTokens(c, req.RefreshToken)
+	if errors.Is(err, middleware.ErrRefreshTokenInvalid) {
+		response.Error(c, response.ErrTokenInvalid, "登录已失效，请重新登录")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "刷新Token失败", "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// GET /api/user/sessions 查看当前在线的设备
+func GetSessions(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	sessions, err := middleware.ListSessions(c, claims.UserId, claims.SessionId)
+	if err != nil {
+		slog.ErrorContext(c, "查询在线设备失败", "userId", claims.UserId, "err", err)
+		response.Error(c, response.ErrDatabase)
+		return
+	}
+	response.Success(c, sessions)
+}
+
+// DELETE /api/user/sessions/:sid 让某个设备下线
+func RevokeSession(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	err := middleware.RevokeSessionById(c, userId, c.Param("sid"))
+	if errors.Is(err, middleware.ErrSessionNotFound) {
+		response.Error(c, response.ErrNotFound, "会话不存在")
+		return
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "下线失败"))
+		return
+	}
+	response.Success(c, gin.H{"message": "设备已下线"})
+}
+
+// PUT /api/user/password 设置或修改密码
+func ChangePassword(c *gin.Context) {
+	userId := c.GetInt64(middleware.CtxKeyUserId)
+	var req changePasswordReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "密码长度必须为6-20位")
+		return
+	}
+	user, err := getUserByIdFromDb(c, userId)
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	//1.已经设置过密码时需要校验旧密码
+	if user.Password != "" {
+		if req.OldPassword == "" {
+			response.Error(c, response.ErrValidation, "请输入原密码")
+			return
+		}
+		if err = checkPassword(c, user, req.OldPassword); err != nil {
+			response.HandleBusinessError(c, err)
+			return
+		}
+	}
+	//2.保存新密码
+	if err = updatePasswordToDb(c, user.ID, req.NewPassword); err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "修改密码失败"))
+		return
+	}
+	response.Success(c, gin.H{"message": "密码修改成功"})
+}
+
+// POST /api/user/password/reset 忘记密码时通过验证码重置，重置后所有设备需要重新登录
+func ResetPassword(c *gin.Context) {
+	var req resetPasswordReq
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, response.ErrValidation, "请求参数格式错误")
+		return
+	}
+	if !isValidPhone(req.Phone) {
+		response.Error(c, response.ErrValidation, "手机号格式有误！")
+		return
+	}
+	//1.防刷检查后校验验证码
+	if !allowLogin(c, req.Phone) {
+		return
+	}
+	if err = checkVerifyCode(c, req.Phone, req.Code); err != nil {
+		response.HandleBusinessError(c, err)
+		return
+	}
+	userQuery := query.TbUser
+	user, err := userQuery.WithContext(c).Where(userQuery.Phone.Eq(req.Phone)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, response.ErrNotFound, "用户不存在")
+		return
+	}
+	if err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, ""))
+		return
+	}
+	//2.保存新密码，解除锁定
+	if err = updatePasswordToDb(c, user.ID, req.NewPassword); err != nil {
+		response.HandleBusinessError(c, response.WrapBusinessError(response.ErrDatabase, err, "重置密码失败"))
+		return
+	}
+	clearPasswordFailures(c, req.Phone)
+	//3.退出所有设备
+	if err = middleware.RevokeAllSessions(c, int64(user.ID)); err != nil {
+		slog.ErrorContext(c, "重置密码后退出所有设备失败", "userId", user.ID, "err", err)
+	}
+	response.Success(c, gin.H{"message": "密码重置成功，请重新登录"})
+}
@@ -0,0 +1,135 @@
+package User
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+	"xzdp/config"
+	"xzdp/db"
+	"xzdp/pkg/ratelimit"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 防刷：发送验证码和登录按手机号、IP做滑动窗口限流，多次触发限流的手机号或IP加入封禁名单
+const (
+	limitKeyPrefix     = "abuse:limit:"     // abuse:limit:{场景}:{phone:xxx|ip:xxx} -> 滑动窗口（ZSet）
+	violationKeyPrefix = "abuse:violation:" // abuse:violation:{phone:xxx|ip:xxx} -> BanWindow内触发限流的次数
+	banKeyPrefix       = "abuse:ban:"       // abuse:ban:{phone:xxx|ip:xxx} -> 封禁名单，过期自动解封
+	codeAttemptsSuffix = ":attempts"        // cache:user:phone:{phone}:attempts -> 当前验证码输错的次数
+)
+
+func phoneSubject(phone string) string {
+	return "phone:" + phone
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// 返回Retry-After响应头和提示
+func abortRetryLater(c *gin.Context, code int, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	response.Error(c, code, fmt.Sprintf("操作过于频繁，请%d秒后再试", seconds))
+}
+
+// 任意一个对象在封禁名单中时返回false，并写好响应
+func checkBanned(c *gin.Context, subjects ...string) bool {
+	for _, subject := range subjects {
+		ttl, err := db.RedisDb.TTL(c, banKeyPrefix+subject).Result()
+		if err != nil {
+			slog.ErrorContext(c, "查询封禁名单失败", "subject", subject, "err", err)
+			continue
+		}
+		if ttl > 0 {
+			abortRetryLater(c, response.ErrTemporarilyBanned, ttl)
+			return false
+		}
+	}
+	return true
+}
+
+// 滑动窗口限流，不通过时记录一次违规并写好响应；Redis出错时放行
+func allowWindow(c *gin.Context, scene string, subject string, settings []config.RateLimitWindow) bool {
+	windows := make([]ratelimit.Window, 0, len(settings))
+	for _, w := range settings {
+		windows = append(windows, ratelimit.Window{Duration: w.Duration, Limit: w.Limit})
+	}
+	ok, retryAfter, err := ratelimit.Allow(c, db.RedisDb, limitKeyPrefix+scene+":"+subject, windows)
+	if err != nil {
+		slog.ErrorContext(c, "限流检查失败", "scene", scene, "subject", subject, "err", err)
+		return true
+	}
+	if !ok {
+		recordViolation(c, subject)
+		abortRetryLater(c, response.ErrTooManyRequests, retryAfter)
+	}
+	return ok
+}
+
+// 记录一次违规，达到阈值时加入封禁名单
+func recordViolation(ctx context.Context, subject string) {
+	opt := config.Current().AntiAbuse
+	if opt.BanThreshold <= 0 {
+		return
+	}
+	key := violationKeyPrefix + subject
+	count, err := db.RedisDb.Incr(ctx, key).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "记录违规次数失败", "subject", subject, "err", err)
+		return
+	}
+	if count == 1 {
+		db.RedisDb.Expire(ctx, key, opt.BanWindow)
+	}
+	if count >= int64(opt.BanThreshold) {
+		db.RedisDb.Set(ctx, banKeyPrefix+subject, count, opt.BanDuration)
+		db.RedisDb.Del(ctx, key)
+		slog.WarnContext(ctx, "触发防刷封禁", "subject", subject, "duration", opt.BanDuration)
+	}
+}
+
+// 发送验证码前的检查：封禁名单、IP限流、手机号限流
+func allowSendCode(c *gin.Context, phone string) bool {
+	opt := config.Current().AntiAbuse
+	ip := ipSubject(c.ClientIP())
+	return checkBanned(c, phoneSubject(phone), ip) &&
+		allowWindow(c, "code", ip, opt.IPWindows) &&
+		allowWindow(c, "code", phoneSubject(phone), opt.PhoneWindows)
+}
+
+// 登录前的检查：封禁名单、IP限流、手机号限流
+// 手机号限流防止换IP对同一个账号撞库
+func allowLogin(c *gin.Context, phone string) bool {
+	opt := config.Current().AntiAbuse
+	ip := ipSubject(c.ClientIP())
+	return checkBanned(c, phoneSubject(phone), ip) &&
+		allowWindow(c, "login", ip, opt.LoginIPWindows) &&
+		allowWindow(c, "login", phoneSubject(phone), opt.LoginPhoneWindows)
+}
+
+// 验证码输错一次，达到上限时验证码失效，需要重新获取
+func recordCodeFailure(ctx context.Context, phone string) error {
+	codeKey := verifyCodeKey(phone)
+	attempts, err := db.RedisDb.Incr(ctx, codeKey+codeAttemptsSuffix).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "记录验证码错误次数失败", "phone", MaskPhoneNumber(phone), "err", err)
+		return response.NewBusinessError(response.ErrPasswordIncorrect, "验证码错误")
+	}
+	if attempts == 1 {
+		db.RedisDb.Expire(ctx, codeKey+codeAttemptsSuffix, codeExpiration)
+	}
+	if attempts >= int64(config.Current().AntiAbuse.MaxCodeAttempts) {
+		if err = db.RedisDb.Del(ctx, codeKey, codeKey+codeAttemptsSuffix).Err(); err != nil {
+			slog.ErrorContext(ctx, "删除验证码失败", "phone", MaskPhoneNumber(phone), "err", err)
+		}
+		recordViolation(ctx, phoneSubject(phone))
+		return response.NewBusinessError(response.ErrCodeAttemptsExceeded, "")
+	}
+	return response.NewBusinessError(response.ErrPasswordIncorrect, "验证码错误")
+}
@@ -0,0 +1,150 @@
+package User
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"xzdp/config"
+	"xzdp/dal/model"
+	"xzdp/dal/query"
+	"xzdp/db"
+	"xzdp/pkg/password"
+	"xzdp/pkg/response"
+
+	"gorm.io/gorm"
+)
+
+// 连续输错密码的次数，key为 user:pwd:fail:{phone}，过期时间就是锁定时长
+const pwdFailKeyPrefix = "user:pwd:fail:"
+
+func passwordParams() password.Params {
+	opt := config.Current().Password
+	return password.Params{
+		Memory:      opt.Memory,
+		Iterations:  opt.Iterations,
+		Parallelism: opt.Parallelism,
+	}
+}
+
+func hashPassword(plain string) (string, error) {
+	return password.Hash(plain, passwordParams())
+}
+
+// 密码错误次数是否已经达到上限
+func isPasswordLocked(ctx context.Context, phone string) bool {
+	failures, err := db.RedisDb.Get(ctx, pwdFailKeyPrefix+phone).Int()
+	return err == nil && failures >= config.Current().Password.MaxFailures
+}
+
+// 记录一次密码错误，返回还能尝试的次数
+func recordPasswordFailure(ctx context.Context, phone string) int {
+	key := pwdFailKeyPrefix + phone
+	failures, err := db.RedisDb.Incr(ctx, key).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "记录密码错误次数失败", "phone", MaskPhoneNumber(phone), "err", err)
+		return 0
+	}
+	opt := config.Current().Password
+	if failures == 1 {
+		db.RedisDb.Expire(ctx, key, opt.LockDuration)
+	}
+	return opt.MaxFailures - int(failures)
+}
+
+// 用户不存在或者没有设置密码时用来验证的哈希，参数与当前配置一致，修改参数后重新生成
+var dummyHash struct {
+	sync.Mutex
+	hash string
+}
+
+// 用户不存在或者没有设置密码时也验证一次密码，响应时间与密码错误相同，不能据此判断手机号是否注册过
+func verifyDummyPassword(plain string) {
+	params := passwordParams()
+	dummyHash.Lock()
+	if dummyHash.hash == "" || password.NeedsRehash(dummyHash.hash, params) {
+		hash, err := password.Hash("xzdp-dummy-password", params)
+		if err != nil {
+			dummyHash.Unlock()
+			slog.Error("生成占位密码哈希失败", "err", err)
+			return
+		}
+		dummyHash.hash = hash
+	}
+	hash := dummyHash.hash
+	dummyHash.Unlock()
+	_, _ = password.Verify(plain, hash)
+}
+
+// 用户不存在、没有设置密码和密码错误都记录一次失败并返回同样的提示，不能据此判断手机号是否注册过
+func passwordFailure(ctx context.Context, phone string) error {
+	left := recordPasswordFailure(ctx, phone)
+	if left <= 0 {
+		return response.NewBusinessError(response.ErrAccountLocked, "")
+	}
+	return response.NewBusinessError(response.ErrPasswordIncorrect, fmt.Sprintf("手机号或密码错误，还可以尝试%d次", left))
+}
+
+func clearPasswordFailures(ctx context.Context, phone string) {
+	db.RedisDb.Del(ctx, pwdFailKeyPrefix+phone)
+}
+
+// 校验密码，失败时计入错误次数，错误次数达到上限时返回账号锁定
+func checkPassword(ctx context.Context, user *model.TbUser, plain string) error {
+	if isPasswordLocked(ctx, user.Phone) {
+		return response.NewBusinessError(response.ErrAccountLocked, "")
+	}
+	ok, err := password.Verify(plain, user.Password)
+	if err != nil {
+		return response.WrapBusinessError(response.ErrUnknown, err, "密码校验失败")
+	}
+	if !ok {
+		return passwordFailure(ctx, user.Phone)
+	}
+	clearPasswordFailures(ctx, user.Phone)
+	//密码哈希参数调整过，按新参数重新哈希，失败不影响登录
+	if password.NeedsRehash(user.Password, passwordParams()) {
+		if err = updatePasswordToDb(ctx, user.ID, plain); err != nil {
+			slog.ErrorContext(ctx, "升级密码哈希失败", "userId", user.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// PasswordLogin 手机号+密码登录，不会自动注册
+func PasswordLogin(ctx context.Context, req loginReqstruct) (*model.TbUser, error) {
+	//1.账号已锁定时不再查库
+	if isPasswordLocked(ctx, req.Phone) {
+		return nil, response.NewBusinessError(response.ErrAccountLocked, "")
+	}
+	//2.用户不存在、没有设置密码和密码错误返回同样的提示
+	userQuery := query.TbUser
+	user, err := userQuery.WithContext(ctx).Where(userQuery.Phone.Eq(req.Phone)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		verifyDummyPassword(req.Password)
+		return nil, passwordFailure(ctx, req.Phone)
+	}
+	if err != nil {
+		return nil, response.WrapBusinessError(response.ErrDatabase, err, "")
+	}
+	if user.Password == "" {
+		verifyDummyPassword(req.Password)
+		return nil, passwordFailure(ctx, req.Phone)
+	}
+	//3.校验密码
+	if err = checkPassword(ctx, user, req.Password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func updatePasswordToDb(ctx context.Context, userId uint64, plain string) error {
+	hash, err := hashPassword(plain)
+	if err != nil {
+		return err
+	}
+	userQuery := query.TbUser
+	_, err = userQuery.WithContext(ctx).Where(userQuery.ID.Eq(userId)).UpdateSimple(userQuery.Password.Value(hash))
+	return err
+}
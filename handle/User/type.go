@@ -1 +1,80 @@
 package User
+
+import (
+	"time"
+	"xzdp/dal/model"
+)
+
+// 用户完整资料，由tb_user和tb_user_info组合而成，缓存在 cache:user:info:{id}
+// 注意不要包含密码等敏感字段
+type userProfile struct {
+	ID        uint64 `json:"id"`
+	Phone     string `json:"phone"`
+	NickName  string `json:"nickName"`
+	Icon      string `json:"icon"`
+	City      string `json:"city"`
+	Introduce string `json:"introduce"`
+	Gender    uint32 `json:"gender"`             // 0：男，1：女
+	Birthday  string `json:"birthday,omitempty"` // 2006-01-02，没有填写时为空
+	Fans      uint32 `json:"fans"`
+	Followee  uint32 `json:"followee"`
+	Credits   uint32 `json:"credits"`
+	Level     uint32 `json:"level"`
+}
+
+// 修改个人资料请求结构体，字段为nil表示不修改
+type updateProfileReq struct {
+	City      *string `json:"city" binding:"omitempty,max=64"`
+	Introduce *string `json:"introduce" binding:"omitempty,max=128"` // 个人介绍，不要超过128个字符
+	Gender    *uint32 `json:"gender" binding:"omitempty,oneof=0 1"`
+	Birthday  *string `json:"birthday" binding:"omitempty"` // 2006-01-02
+}
+
+const birthdayLayout = "2006-01-02"
+
+func newUserProfile(user *model.TbUser, info *model.TbUserInfo) *userProfile {
+	p := &userProfile{
+		ID:       user.ID,
+		Phone:    user.Phone,
+		NickName: user.NickName,
+		Icon:     user.Icon,
+	}
+	if info != nil {
+		p.City = info.City
+		p.Introduce = info.Introduce
+		p.Gender = info.Gender
+		p.Fans = info.Fans
+		p.Followee = info.Followee
+		p.Credits = info.Credits
+		p.Level = info.Level
+		if !info.Birthday.IsZero() {
+			p.Birthday = info.Birthday.Format(birthdayLayout)
+		}
+	}
+	return p
+}
+
+// 校验并转换为需要更新的列，生日必须是合法日期并且不能晚于今天
+func (r *updateProfileReq) toColumns() (map[string]any, error) {
+	columns := make(map[string]any)
+	if r.City != nil {
+		columns["city"] = *r.City
+	}
+	if r.Introduce != nil {
+		columns["introduce"] = *r.Introduce
+	}
+	if r.Gender != nil {
+		columns["gender"] = *r.Gender
+	}
+	if r.Birthday != nil {
+		birthday, err := time.ParseInLocation(birthdayLayout, *r.Birthday, time.Local)
+		if err != nil {
+			return nil, errInvalidBirthday
+		}
+		if birthday.After(time.Now()) || birthday.Year() < 1900 {
+			return nil, errInvalidBirthday
+		}
+		columns["birthday"] = birthday
+	}
+	return columns, nil
+}
@@ -2,51 +2,117 @@ package User
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"strconv"
-	"time"
+	"xzdp/config"
 	"xzdp/dal/model"
 	"xzdp/dal/query"
 	"xzdp/db"
+	"xzdp/handle/User/UserCache"
+	"xzdp/pkg/metrics"
 	"xzdp/pkg/response"
 
 	"github.com/bytedance/sonic"
+	"gorm.io/gorm"
 )
 
-func getUserByIdFromDb(id int64) (*model.TbUser, error) {
+func getUserByIdFromDb(ctx context.Context, id int64) (*model.TbUser, error) {
 	userQuery := query.TbUser
-	return userQuery.Where(userQuery.ID.Eq(uint64(id))).First()
+	return userQuery.WithContext(ctx).Where(userQuery.ID.Eq(uint64(id))).First()
 }
 
-func UpdateUserInfoById(user *model.TbUser) error {
+// 只更新非零字段，避免把头像等没有传的字段覆盖为空
+func UpdateUserInfoById(ctx context.Context, user *model.TbUser) error {
 	userQuery := query.TbUser
-	return userQuery.Save(user)
+	_, err := userQuery.WithContext(ctx).Where(userQuery.ID.Eq(user.ID)).Updates(user)
+	return err
 }
 
-func getUserByIdFromCache(id string) (*model.TbUser, error) {
-	res, err := db.RedisDb.Get(context.Background(), userPrefix+inforKeyPrefix+":"+id).Result()
+// 从数据库查询完整资料，tb_user_info中还没有记录时只返回tb_user中的字段
+func getProfileFromDb(ctx context.Context, id int64) (*userProfile, error) {
+	user, err := getUserByIdFromDb(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	infoQuery := query.TbUserInfo
+	info, err := infoQuery.WithContext(ctx).Where(infoQuery.UserID.Eq(uint64(id))).First()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return newUserProfile(user, info), nil
+}
+
+// 先查缓存，未命中时查数据库并写回缓存
+func loadProfile(ctx context.Context, id int64) (*userProfile, error) {
+	profile, err := getProfileFromCache(ctx, strconv.FormatInt(id, 10))
+	if profile != nil && err == nil {
+		return profile, nil
+	}
+	profile, err = getProfileFromDb(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NewBusinessError(response.ErrNotFound, "用户不存在")
+		}
+		return nil, response.WrapBusinessError(response.ErrDatabase, err, "")
+	}
+	if err = setProfileToCache(ctx, profile); err != nil {
+		// 缓存设置失败不影响返回，只记录日志
+		slog.ErrorContext(ctx, "设置用户缓存失败", "err", err)
+	}
+	return profile, nil
+}
+
+// 修改tb_user_info，记录不存在时先创建
+func updateUserInfoColumns(ctx context.Context, userId uint64, columns map[string]any) error {
+	q := query.Use(db.DBEngine)
+	return q.Transaction(func(tx *query.Query) error {
+		info := tx.TbUserInfo
+		count, err := info.WithContext(ctx).Where(info.UserID.Eq(userId)).Count()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			err = info.WithContext(ctx).Omit(info.Birthday).Create(&model.TbUserInfo{UserID: userId})
+			if err != nil {
+				return err
+			}
+		}
+		_, err = info.WithContext(ctx).Where(info.UserID.Eq(userId)).Updates(columns)
+		return err
+	})
+}
+
+func updateUserIcon(ctx context.Context, userId uint64, icon string) error {
+	userQuery := query.TbUser
+	_, err := userQuery.WithContext(ctx).Where(userQuery.ID.Eq(userId)).UpdateSimple(userQuery.Icon.Value(icon))
+	return err
+}
+
+func getProfileFromCache(ctx context.Context, id string) (*userProfile, error) {
+	res, err := db.RedisDb.Get(ctx, UserCache.InfoKeyPrefix+id).Result()
+	metrics.CacheLookup(UserCache.InfoKeyPrefix, res != "" && err == nil)
 	if res == "" || err != nil {
 		return nil, response.NewBusinessError(response.ErrExpired, "用户信息不存在或已过期")
 	}
-	var user model.TbUser
-	err = sonic.Unmarshal([]byte(res), &user)
+	var profile userProfile
+	err = sonic.Unmarshal([]byte(res), &profile)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return &profile, nil
 }
 
-func setUserToCache(user *model.TbUser) error {
-	b, err := sonic.Marshal(user)
+func setProfileToCache(ctx context.Context, profile *userProfile) error {
+	b, err := sonic.Marshal(profile)
 	if err != nil {
 		return err
 	}
-	return db.RedisDb.Set(context.Background(),
-		userPrefix+inforKeyPrefix+":"+strconv.FormatUint(user.ID, 10),
-		string(b), time.Duration(userInfoCacheTTL)).Err()
+	return db.RedisDb.Set(ctx, UserCache.InfoKey(profile.ID), string(b), config.Current().Cache.UserInfo).Err()
 }
 
-func deleteUserInfoFromCache(id string) error {
-	return db.RedisDb.Del(context.Background(), userPrefix+inforKeyPrefix+":"+id).Err()
+func deleteUserInfoFromCache(ctx context.Context, id string) error {
+	return db.RedisDb.Del(ctx, UserCache.InfoKeyPrefix+id).Err()
 }
 
 // 处理成脱敏手机号134****3310
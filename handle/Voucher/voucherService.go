@@ -3,7 +3,6 @@ package Voucher
 import (
 	"log/slog"
 	"strconv"
-	"time"
 	"xzdp/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -13,7 +12,6 @@ const (
 	timeLayout       = "2006-01-02 15:04:05"
 	timeFormatError  = "time format error, must be like 2006-01-02 15:04:05"
 	voucherKeyPrefix = "voucher:shop:"
-	voucherTTL       = 30 * time.Second
 )
 
 func AddVoucher(c *gin.Context) {
@@ -22,9 +20,9 @@ func AddVoucher(c *gin.Context) {
 	VoucherType := voucherReq.Type
 	var id uint64
 	if VoucherType == 0 {
-		id, err = AddDinaryVoucherToDB(DTOToVoucherModel(voucherReq))
+		id, err = AddDinaryVoucherToDB(c, DTOToVoucherModel(voucherReq))
 	} else if VoucherType == 1 {
-		id, err = AddSeckillVoucherToDB(voucherReq)
+		id, err = AddSeckillVoucherToDB(c, voucherReq)
 	}
 	response.HandleBusinessResult(c, err, gin.H{"voucherId": id})
 }
@@ -35,7 +33,7 @@ func GetVouchersByShopId(c *gin.Context) {
 		response.Error(c, response.ErrValidation, "无效参数")
 	}
 	CacheKey := voucherKeyPrefix + shopId
-	CacheRes, err := getVouchersFromCache(CacheKey)
+	CacheRes, err := getVouchersFromCache(c, CacheKey)
 	if CacheRes != nil && err == nil {
 		response.Success(c, CacheRes)
 		return
@@ -44,13 +42,13 @@ func GetVouchersByShopId(c *gin.Context) {
 	if err != nil {
 		return
 	}
-	resDb, err := getVouchersFromDB(Id)
+	resDb, err := getVouchersFromDB(c, Id)
 	if err != nil {
 		response.Error(c, response.ErrDatabaseNotFind)
 	}
 	response.Success(c, resDb)
-	err = setVouchersToCache(CacheKey, resDb)
+	err = setVouchersToCache(c, CacheKey, resDb)
 	if err != nil {
-		slog.Log(c, 1, "博客缓存失败")
+		slog.ErrorContext(c, "优惠券缓存失败", "shopId", shopId, "err", err)
 	}
 }
@@ -3,24 +3,26 @@ package Voucher
 import (
 	"context"
 	"time"
+	"xzdp/config"
 	"xzdp/dal/model"
 	"xzdp/dal/query"
 	"xzdp/db"
+	"xzdp/pkg/metrics"
 	"xzdp/pkg/response"
 
 	"github.com/bytedance/sonic"
 )
 
-func AddDinaryVoucherToDB(v model.TbVoucher) (uint64, error) {
+func AddDinaryVoucherToDB(ctx context.Context, v model.TbVoucher) (uint64, error) {
 	VourcherQuery := query.TbVoucher
-	err := VourcherQuery.Create(&v)
+	err := VourcherQuery.WithContext(ctx).Create(&v)
 	if err != nil {
 		return 0, response.WrapBusinessError(response.ErrDatabase, err, "")
 	}
 	return v.ID, nil
 }
 
-func AddSeckillVoucherToDB(v VoucherDTO) (uint64, error) {
+func AddSeckillVoucherToDB(ctx context.Context, v VoucherDTO) (uint64, error) {
 
 	start, err := time.Parse(timeLayout, v.BeginTime)
 	if err != nil {
@@ -40,7 +42,7 @@ func AddSeckillVoucherToDB(v VoucherDTO) (uint64, error) {
 	q := query.Use(db.DBEngine) //初始化查询对象（Query）并关联数据库连接
 	err = q.Transaction(func(tx *query.Query) error {
 		// 1.先insert到Voucher表
-		err := tx.TbVoucher.Create(&voucherDbModel)
+		err := tx.TbVoucher.WithContext(ctx).Create(&voucherDbModel)
 		if err != nil {
 			return response.WrapBusinessError(response.ErrDatabase, err, "Voucher表插入失败")
 		}
@@ -52,7 +54,7 @@ func AddSeckillVoucherToDB(v VoucherDTO) (uint64, error) {
 			BeginTime: start,
 			EndTime:   end,
 		}
-		err = tx.TbSeckillVoucher.Create(&seckillDbModel)
+		err = tx.TbSeckillVoucher.WithContext(ctx).Create(&seckillDbModel)
 		if err != nil {
 			return response.WrapBusinessError(response.ErrDatabase, err, "SeckillVoucher表插入失败")
 		}
@@ -63,11 +65,11 @@ func AddSeckillVoucherToDB(v VoucherDTO) (uint64, error) {
 }
 
 // 获取某个商家的全部优惠券
-func getVouchersFromDB(shopId int64) ([]*VoucherDTO, error) {
+func getVouchersFromDB(ctx context.Context, shopId int64) ([]*VoucherDTO, error) {
 	v := query.TbVoucher
 	sv := query.TbSeckillVoucher
 	var result []*VoucherDTO
-	err := v.LeftJoin(sv, v.ID.EqCol(sv.VoucherID)).
+	err := v.WithContext(ctx).LeftJoin(sv, v.ID.EqCol(sv.VoucherID)).
 		Where(v.ShopID.Eq(uint64(shopId))).
 		Select(
 			v.ID, v.ShopID, v.Title, v.SubTitle, v.Rules,
@@ -79,8 +81,9 @@ func getVouchersFromDB(shopId int64) ([]*VoucherDTO, error) {
 	return result, err
 }
 
-func getVouchersFromCache(CacheKey string) ([]*model.TbVoucher, error) {
-	CacheRes, err := db.RedisDb.Get(context.Background(), CacheKey).Result()
+func getVouchersFromCache(ctx context.Context, CacheKey string) ([]*model.TbVoucher, error) {
+	CacheRes, err := db.RedisDb.Get(ctx, CacheKey).Result()
+	metrics.CacheLookup(voucherKeyPrefix, err == nil)
 	var res []*model.TbVoucher
 	err = sonic.Unmarshal([]byte(CacheRes), res)
 	if err != nil {
@@ -89,10 +92,10 @@ func getVouchersFromCache(CacheKey string) ([]*model.TbVoucher, error) {
 	return res, nil
 }
 
-func setVouchersToCache(CacheKey string, vouchers []*VoucherDTO) error {
+func setVouchersToCache(ctx context.Context, CacheKey string, vouchers []*VoucherDTO) error {
 	vouchersJson, err := sonic.Marshal(vouchers)
 	if vouchersJson == nil || err != nil {
 		return err
 	}
-	return db.RedisDb.Set(context.Background(), CacheKey, string(vouchersJson), voucherTTL).Err()
+	return db.RedisDb.Set(ctx, CacheKey, string(vouchersJson), config.Current().Cache.Voucher).Err()
 }
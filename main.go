@@ -1,10 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 	"xzdp/config"
 	"xzdp/db"
+	"xzdp/db/migrate"
+	"xzdp/handle/Account"
+	"xzdp/handle/Admin"
+	"xzdp/handle/OAuth"
+	"xzdp/handle/Sign"
+	"xzdp/middleware"
+	"xzdp/pkg/health"
 	"xzdp/pkg/logger"
+	"xzdp/pkg/sms"
+	"xzdp/pkg/tracing"
 	"xzdp/router"
 
 	"github.com/spf13/pflag"
@@ -12,27 +29,183 @@ import (
 
 func init() {
 	configPath := pflag.StringP("config", "c", "configs/config.yaml", "config file path")
+	overrides := pflag.StringArray("set", nil, "override a config value, e.g. --set mysql.host=db:3306")
+	//遇到子命令后停止解析，子命令的参数由子命令自己解析，所以全局参数要写在子命令前面
+	pflag.CommandLine.SetInterspersed(false)
 	pflag.Parse()
 
-	config.InitConfig(*configPath)      //初始化配置
-	logger.InitLogger(config.LogOption) //初始化日志
+	config.InitConfig(*configPath, *overrides) //初始化配置
+	logger.InitLogger(config.LogOption)        //初始化日志
+}
+
+// 启动服务需要的初始化，子命令不需要
+func initServer() {
+	//初始化链路追踪
+	traceOpt := config.TraceOption
+	exporter, err := tracing.NewExporter(traceOpt.Exporter, traceOpt.File, traceOpt.Endpoint, traceOpt.Insecure)
+	if err != nil {
+		panic(err)
+	}
+	if err = tracing.InitTracing(exporter, traceOpt.ServiceName, traceOpt.SampleRatio); err != nil {
+		panic(err)
+	}
+	//加载JWT密钥
+	if err := middleware.InitKeySet(config.JwtOption); err != nil {
+		panic(err)
+	}
+	//初始化短信
+	smsOpt := config.SMSOption
+	sender, err := sms.NewSender(smsOpt.Provider, smsOpt.File, smsOpt.URL, smsOpt.APIKey, smsOpt.Timeout)
+	if err != nil {
+		panic(err)
+	}
+	sms.InitSMS(sender, smsOpt.Templates)
+	//初始化第三方登录
+	OAuth.InitProviders(config.OAuthOption.Providers)
 	slog.Info("MySQL和Redis配置成功")
-	var err error
 	//初始化数据库
 	db.DBEngine, err = db.NewMySQL(config.MysqlOption)
 	if err != nil {
 		panic(err)
 	}
+	//表结构不是最新的时候拒绝启动
+	sqlDB, err := db.DBEngine.DB()
+	if err != nil {
+		panic(err)
+	}
+	if err = migrate.Check(context.Background(), sqlDB); err != nil {
+		panic(fmt.Errorf("%w, run `xzdp migrate up` first", err))
+	}
 	db.RedisDb, err = db.NewRedisClient(config.RedisOption)
 	if err != nil {
 		panic(err)
 	}
+	//就绪检查
+	health.Register("mysql", db.PingMySQL)
+	health.Register("redis", db.PingRedis)
+	//配置热加载：日志等级和连接池大小需要主动应用，其余配置在使用时读取
+	config.OnChange(func(old, new *config.Config) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
+		}
+		if old.Mysql.MaxOpenConns != new.Mysql.MaxOpenConns || old.Mysql.MaxIdleConns != new.Mysql.MaxIdleConns {
+			if err := db.SetPoolSize(new.Mysql); err != nil {
+				slog.Error("调整数据库连接池失败", "err", err)
+			}
+		}
+	})
 }
 
 func main() {
-	r := router.NewRouter()
-	err := r.Run(":" + config.ServerOption.HttpPort)
-	if err != nil {
+	//子命令
+	switch pflag.Arg(0) {
+	case "":
+	case "migrate":
+		if err := runMigrate(pflag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			os.Exit(1)
+		}
+		return
+	case "seed":
+		if err := runSeed(pflag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "seed:", err)
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, available commands: migrate, seed\n", pflag.Arg(0))
+		os.Exit(2)
+	}
+	initServer()
+	//写入默认的角色和权限
+	if err := Admin.InitRoles(context.Background()); err != nil {
+		panic(err)
+	}
+	//后台任务，退出时取消workerCtx并等待它们结束
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	//后台任务：签到记录归档
+	Sign.StartArchiver(workerCtx, &workers)
+	//后台任务：注销冷静期结束的账号
+	Account.StartPurger(workerCtx, &workers)
+	//后台任务：MySQL从库健康检查
+	db.StartReplicaChecker(workerCtx, &workers)
+
+	srv := &http.Server{
+		Addr:         ":" + config.ServerOption.HttpPort,
+		Handler:      router.NewRouter(),
+		ReadTimeout:  config.ServerOption.ReadTimeout,
+		WriteTimeout: config.ServerOption.WriteTimeout,
+		IdleTimeout:  config.ServerOption.IdleTimeout,
+	}
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("服务启动", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+	//Prometheus指标单独监听，不和业务接口共用端口
+	var metricsSrv *http.Server
+	if addr := config.ServerOption.MetricsAddr; addr != "" {
+		metricsSrv = &http.Server{
+			Addr:         addr,
+			Handler:      router.NewMetricsRouter(),
+			ReadTimeout:  config.ServerOption.ReadTimeout,
+			WriteTimeout: config.ServerOption.WriteTimeout,
+			IdleTimeout:  config.ServerOption.IdleTimeout,
+		}
+		go func() {
+			slog.Info("指标服务启动", "addr", addr)
+			serveErr <- metricsSrv.ListenAndServe()
+		}()
+	}
+
+	//等待SIGINT或SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serveErr:
+		//端口被占用等原因启动失败
 		panic(err)
+	case <-ctx.Done():
+	}
+	stop() //再次收到信号时直接退出
+	slog.Info("收到退出信号，开始优雅退出")
+	//先报告未就绪，等负载均衡摘掉流量后再停止接受请求
+	health.SetShuttingDown()
+	time.Sleep(config.ServerOption.ShutdownDelay)
+
+	timeout := config.ServerOption.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	//1.不再接受新请求，等待处理中的请求（比如秒杀下单的事务）完成
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("等待处理中的请求超时", "err", err)
+	}
+	if metricsSrv != nil {
+		_ = metricsSrv.Shutdown(shutdownCtx)
+	}
+	//2.停止后台任务
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		slog.Error("等待后台任务退出超时")
+	}
+	//3.关闭数据库连接
+	if err := db.Close(); err != nil {
+		slog.Error("关闭数据库连接失败", "err", err)
+	}
+	//4.发送剩余的span
+	if err := tracing.Shutdown(shutdownCtx); err != nil {
+		slog.Error("发送链路数据失败", "err", err)
 	}
+	slog.Info("服务已退出")
 }
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 探针每隔几秒就会访问一次，不记录访问日志
+var accessLogSkipPaths = map[string]struct{}{
+	"/healthz": {},
+	"/readyz":  {},
+}
+
+// AccessLog 结构化的访问日志，替换gin默认的文本日志
+// 请求id、用户id、路由和IP由 RequestID 和 OptionalJWT 放进context，这里不用重复记录
+// 只记录path不记录query，query中可能带有验证码等敏感参数
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := accessLogSkipPaths[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latencyMs", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("size", c.Writer.Size()),
+			slog.String("userAgent", c.Request.UserAgent()),
+		}
+		if bizCode, ok := c.Get(response.CtxKeyBizCode); ok {
+			attrs = append(attrs, slog.Int("bizCode", bizCode.(int)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		// c.Request在后面的中间件中可能被替换（比如OptionalJWT加上了用户id），所以用处理完之后的
+		slog.LogAttrs(c.Request.Context(), level, "access", attrs...)
+	}
+}
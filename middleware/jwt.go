@@ -1,53 +1,55 @@
 package middleware
 
 import (
-	"fmt"
+	"log/slog"
 	"time"
 	"xzdp/config"
+	"xzdp/pkg/logger"
 	"xzdp/pkg/response"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// 避免在 JWT 的 payload 中存储敏感的用户信息。因为 JWT 通常是可解码的，虽然签名可以保证其完整性，
+// 但不能保证其保密性。所以令牌中只放用户id，手机号等信息需要时由服务端根据用户id查询。
 type userClaims struct {
 	UserId               int64
-	Phone                string
-	jwt.RegisteredClaims // v5版本新加的方法
+	SessionId            string   `json:"sid"`             // 会话id，同一次登录刷新出来的令牌共用一个会话
+	Roles                []string `json:"roles,omitempty"` // 签发时用户的角色
+	RoleVersion          int64    `json:"rv,omitempty"`    // 签发时用户角色的版本号，与Redis中的不一致说明角色已经变化
+	jwt.RegisteredClaims          // v5版本新加的方法，ID字段就是jti
 }
 
-// 避免在 JWT 的 payload 中存储敏感的用户信息。因为 JWT 通常是可解码的，虽然签名可以保证其完整性，
-// 但不能保证其保密性。如果需要存储一些用户相关的信息，可以使用加密的方式存储在服务器端，并在 JWT 中存储一个引用或标识符。
-// 所以要对号码进行加密，或者使用其他不敏感的信息。
-
 // 生成Token
-func GenerateToken(phone string, userId int64) (string, error) {
-	// 1. 接收手机号和userId
+func GenerateToken(userId int64, sessionId string, roles []string, roleVersion int64) (string, error) {
+	// 1. 接收userId、会话id和角色，并生成唯一的jti用于注销
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
 	// 2. 创建userClaims对象
 	claims := userClaims{
-		Phone:  phone,  //手机号
-		UserId: userId, //用户ID
+		UserId:      userId,      //用户ID
+		SessionId:   sessionId,   //会话ID
+		Roles:       roles,       //角色
+		RoleVersion: roleVersion, //角色版本号
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,                                                         //令牌ID
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.JwtOption.Expire)), //过期时间
 			Issuer:    config.JwtOption.Issuer,                                     //签发者
+			IssuedAt:  jwt.NewNumericDate(time.Now()),                              //签发时间
 			NotBefore: jwt.NewNumericDate(time.Now()),                              //生效时间
 		},
 	}
-	// 3. 使用 HS256 算法和配置中的密钥对 claims 进行签名
-	tokenStruct := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// 4. 获得完整的签名的令牌
-	return tokenStruct.SignedString([]byte(config.JwtOption.Secret))
+	// 3. 使用当前的签名密钥对 claims 进行签名，头部带上kid
+	return signToken(claims)
 }
 
 // 解析Token
 func ParseToken(token string) (*userClaims, error) {
-	tokenClaims, err := jwt.ParseWithClaims(token, &userClaims{}, func(token *jwt.Token) (any, error) {
-		// 验证签名方法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.JwtOption.Secret), nil
-	})
+	// 按kid查找验证密钥，同时验证签名方法
+	tokenClaims, err := jwt.ParseWithClaims(token, &userClaims{}, lookupVerifyKey)
 	if err != nil {
 		return nil, err
 	}
@@ -61,11 +63,21 @@ func ParseToken(token string) (*userClaims, error) {
 }
 
 const (
-	CtxKeyUserPhone       = "userPhone"
 	CtxKeyUserId          = "userId"
 	CtxKeyIsAuthenticated = "isAuthenticated"
+	CtxKeyClaims          = "claims"
 )
 
+// GetClaims 获取当前请求的令牌信息，未认证时返回nil
+func GetClaims(c *gin.Context) *userClaims {
+	v, ok := c.Get(CtxKeyClaims)
+	if !ok {
+		return nil
+	}
+	claims, _ := v.(*userClaims)
+	return claims
+}
+
 // OptionalJWT 作为可选验证，无论是否提供令牌都会放行请求，但会在上下文中标记认证状态
 func OptionalJWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -99,17 +111,30 @@ func OptionalJWT() gin.HandlerFunc {
 		}
 
 		// 验证关键字段是否为空
-		if claims.Phone == "" || claims.UserId == 0 {
+		if claims.UserId == 0 {
 			// 字段为空，设置未认证状态
 			c.Set(CtxKeyIsAuthenticated, false)
 			c.Next()
 			return
 		}
 
+		// 已经退出登录的令牌；没有jti的是旧版本签发的令牌，其中的手机号解析时会被忽略
+		revoked := isTokenRevoked
+		if claims.ID == "" {
+			revoked = isLegacyTokenRevoked
+		}
+		if revoked(c, claims) {
+			c.Set(CtxKeyIsAuthenticated, false)
+			c.Next()
+			return
+		}
+
 		// token有效且字段完整，设置用户信息
-		c.Set(CtxKeyUserPhone, claims.Phone)
 		c.Set(CtxKeyUserId, claims.UserId)
+		c.Set(CtxKeyClaims, claims)
 		c.Set(CtxKeyIsAuthenticated, true)
+		// 之后的日志都带上用户id
+		c.Request = c.Request.WithContext(logger.AppendCtx(c.Request.Context(), slog.Int64(CtxKeyUserId, claims.UserId)))
 		c.Next()
 	}
 }
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"xzdp/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWT密钥集：
+// 1. 签发令牌时使用 JWT.SigningKey 指定的密钥，并把它的kid写入令牌头部
+// 2. 验证令牌时按头部的kid查找 JWT.Keys 中的密钥，所以轮换密钥时新旧密钥可以同时生效
+// 3. 非对称密钥（RS256/ES256/EdDSA）的公钥通过JWKS接口公开，其他服务可以直接验证我们的令牌
+// 轮换步骤：把新密钥加入Keys并改SigningKey，旧密钥保留到它签发的最后一个令牌过期（JWT.Expire）后再删除
+
+var ErrUnknownKid = errors.New("unknown jwt kid")
+
+type jwtKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   any // 只有公钥的密钥为nil，只能用于验证
+	verifyKey any
+}
+
+var (
+	signingKey *jwtKey
+	verifyKeys map[string]*jwtKey
+)
+
+// InitKeySet 加载配置中的密钥
+func InitKeySet(setting *config.JWTSetting) error {
+	keys := make(map[string]*jwtKey, len(setting.Keys))
+	for _, k := range setting.Keys {
+		key, err := loadKey(k)
+		if err != nil {
+			return fmt.Errorf("load jwt key %q: %w", k.Kid, err)
+		}
+		keys[key.kid] = key
+	}
+	// 旧版本签发的令牌头部没有kid，继续用 JWT.Secret 验证，轮换完成后把Secret删掉即可；没有配置Keys时也用它签发
+	if setting.Secret != "" {
+		legacy := &jwtKey{method: jwt.SigningMethodHS256, verifyKey: []byte(setting.Secret)}
+		if len(keys) == 0 {
+			legacy.signKey = legacy.verifyKey
+		}
+		keys[""] = legacy
+	}
+	signing, ok := keys[setting.SigningKey]
+	if !ok || signing.signKey == nil {
+		return fmt.Errorf("jwt signing key %q not found or has no private key", setting.SigningKey)
+	}
+	signingKey, verifyKeys = signing, keys
+	return nil
+}
+
+func loadKey(k config.JWTKey) (*jwtKey, error) {
+	if k.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+	key := &jwtKey{kid: k.Kid}
+	var err error
+	switch strings.ToUpper(k.Alg) {
+	case "HS256":
+		if k.Secret == "" {
+			return nil, errors.New("secret is required")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey, key.verifyKey = []byte(k.Secret), []byte(k.Secret)
+		return key, nil
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+		err = loadPEM(k, key, jwt.ParseRSAPrivateKeyFromPEM, jwt.ParseRSAPublicKeyFromPEM,
+			func(priv *rsa.PrivateKey) any { return &priv.PublicKey })
+	case "ES256":
+		key.method = jwt.SigningMethodES256
+		err = loadPEM(k, key, jwt.ParseECPrivateKeyFromPEM, jwt.ParseECPublicKeyFromPEM,
+			func(priv *ecdsa.PrivateKey) any { return &priv.PublicKey })
+		if pub, ok := key.verifyKey.(*ecdsa.PublicKey); err == nil && ok && pub.Curve != elliptic.P256() {
+			err = errors.New("ES256 requires a P-256 key")
+		}
+	case "EDDSA":
+		key.method = jwt.SigningMethodEdDSA
+		err = loadPEM(k, key, jwt.ParseEdPrivateKeyFromPEM, jwt.ParseEdPublicKeyFromPEM,
+			func(priv crypto.PrivateKey) any { return priv.(ed25519.PrivateKey).Public() })
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", k.Alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// 有私钥时从私钥推出公钥，只有公钥时只能用于验证
+func loadPEM[P any, V any](k config.JWTKey, key *jwtKey, parsePriv func([]byte) (P, error), parsePub func([]byte) (V, error), public func(P) any) error {
+	if k.PrivateKeyFile != "" {
+		data, err := os.ReadFile(k.PrivateKeyFile)
+		if err != nil {
+			return err
+		}
+		priv, err := parsePriv(data)
+		if err != nil {
+			return err
+		}
+		key.signKey, key.verifyKey = priv, public(priv)
+		return nil
+	}
+	if k.PublicKeyFile == "" {
+		return errors.New("privateKeyFile or publicKeyFile is required")
+	}
+	data, err := os.ReadFile(k.PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	pub, err := parsePub(data)
+	if err != nil {
+		return err
+	}
+	key.verifyKey = pub
+	return nil
+}
+
+// 按令牌头部的kid查找验证密钥，算法必须与密钥一致，防止算法混淆攻击
+func lookupVerifyKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKid, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// 使用当前签名密钥签名
+func signToken(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(signingKey.method, claims)
+	if signingKey.kid != "" {
+		token.Header["kid"] = signingKey.kid
+	}
+	return token.SignedString(signingKey.signKey)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// 只公开非对称密钥的公钥，HMAC密钥绝对不能公开
+func toJWK(key *jwtKey) (jwk, bool) {
+	res := jwk{Kid: key.kid, Alg: key.method.Alg(), Use: "sig"}
+	switch pub := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		res.Kty = "RSA"
+		res.N = b64(pub.N.Bytes())
+		res.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		res.Kty, res.Crv = "EC", "P-256"
+		res.X = b64(pub.X.FillBytes(make([]byte, 32)))
+		res.Y = b64(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		res.Kty, res.Crv = "OKP", "Ed25519"
+		res.X = b64(pub)
+	default:
+		return res, false
+	}
+	return res, true
+}
+
+// JWKS GET /.well-known/jwks.json 返回标准的JWKS格式，不使用统一响应结构
+func JWKS(c *gin.Context) {
+	keys := make([]jwk, 0, len(verifyKeys))
+	for _, key := range verifyKeys {
+		if k, ok := toJWK(key); ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
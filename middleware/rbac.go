@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+	"xzdp/dal/query"
+	"xzdp/db"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// 基于角色的权限控制：
+// 1. 角色和权限保存在 tb_role、tb_permission、tb_role_permission，用户的角色保存在 tb_user_role
+// 2. 签发令牌时把用户当前的角色和角色版本号写入令牌
+// 3. 授权/撤销角色时版本号加1，令牌中的版本号与Redis中的不一致时重新查询角色，所以修改立即生效，不需要重新登录
+
+// 角色
+const (
+	RoleUser      = "user" // 所有登录用户默认拥有，不需要写入tb_user_role
+	RoleMerchant  = "merchant"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin" // 拥有所有权限
+)
+
+// 权限，格式为 资源:操作
+const (
+	PermShopWrite    = "shop:write"
+	PermVoucherWrite = "voucher:write"
+	PermBlogModerate = "blog:moderate"
+	PermUserRole     = "user:role"
+	PermConfigRead   = "config:read"
+)
+
+const (
+	userRolesKeyPrefix   = "auth:roles:"    // auth:roles:{userId} -> 用户当前的角色（Set缓存）
+	roleVersionKeyPrefix = "auth:role_ver:" // auth:role_ver:{userId} -> 用户角色的版本号
+	userRolesCacheTTL    = 10 * time.Minute
+	// 角色拥有的权限很少变化，缓存在内存中定时刷新
+	rolePermsRefreshInterval = time.Minute
+)
+
+var rolePerms struct {
+	sync.RWMutex
+	perms    map[string]map[string]struct{}
+	loadedAt time.Time
+}
+
+// 查询用户当前的角色和角色版本号，先查缓存，未命中时查数据库并写回缓存
+func loadUserRoles(ctx context.Context, userId int64) ([]string, int64, error) {
+	id := strconv.FormatInt(userId, 10)
+	pipe := db.RedisDb.Pipeline()
+	verCmd := pipe.Get(ctx, roleVersionKeyPrefix+id)
+	rolesCmd := pipe.SMembers(ctx, userRolesKeyPrefix+id)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+	ver, _ := verCmd.Int64()
+	// 缓存中至少有user角色，为空说明没有缓存
+	if roles := rolesCmd.Val(); len(roles) > 0 {
+		return roles, ver, nil
+	}
+	roles, err := getUserRolesFromDB(ctx, uint64(userId))
+	if err != nil {
+		return nil, 0, err
+	}
+	members := make([]any, 0, len(roles))
+	for _, r := range roles {
+		members = append(members, r)
+	}
+	pipe = db.RedisDb.TxPipeline()
+	pipe.SAdd(ctx, userRolesKeyPrefix+id, members...)
+	pipe.Expire(ctx, userRolesKeyPrefix+id, userRolesCacheTTL)
+	if _, err = pipe.Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "设置用户角色缓存失败", "userId", userId, "err", err)
+	}
+	return roles, ver, nil
+}
+
+func getUserRolesFromDB(ctx context.Context, userId uint64) ([]string, error) {
+	r, ur := query.TbRole, query.TbUserRole
+	var codes []string
+	//授权和撤销后马上会重新查询，读主库避免从库延迟
+	err := r.WithContext(ctx).WriteDB().Join(ur, ur.RoleID.EqCol(r.ID)).
+		Where(ur.UserID.Eq(userId)).Pluck(r.Code, &codes)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{RoleUser}, codes...), nil
+}
+
+// InvalidateUserRoles 用户的角色变化后调用，已经签发的令牌会在下一次请求时重新查询角色
+func InvalidateUserRoles(ctx context.Context, userId int64) error {
+	id := strconv.FormatInt(userId, 10)
+	pipe := db.RedisDb.TxPipeline()
+	pipe.Incr(ctx, roleVersionKeyPrefix+id)
+	pipe.Del(ctx, userRolesKeyPrefix+id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// 角色对应的权限，超过刷新间隔时重新从数据库加载
+func getRolePerms(ctx context.Context) (map[string]map[string]struct{}, error) {
+	rolePerms.RLock()
+	perms, loadedAt := rolePerms.perms, rolePerms.loadedAt
+	rolePerms.RUnlock()
+	if perms != nil && time.Since(loadedAt) < rolePermsRefreshInterval {
+		return perms, nil
+	}
+	var rows []struct {
+		Role string
+		Perm string
+	}
+	rp, r, p := query.TbRolePermission, query.TbRole, query.TbPermission
+	err := rp.WithContext(ctx).WriteDB().Select(r.Code.As("role"), p.Code.As("perm")).
+		Join(r, r.ID.EqCol(rp.RoleID)).Join(p, p.ID.EqCol(rp.PermissionID)).Scan(&rows)
+	if err != nil {
+		// 数据库出错时继续使用之前加载的权限
+		if perms != nil {
+			slog.ErrorContext(ctx, "刷新角色权限失败", "err", err)
+			return perms, nil
+		}
+		return nil, err
+	}
+	perms = make(map[string]map[string]struct{})
+	for _, row := range rows {
+		if perms[row.Role] == nil {
+			perms[row.Role] = make(map[string]struct{})
+		}
+		perms[row.Role][row.Perm] = struct{}{}
+	}
+	rolePerms.Lock()
+	rolePerms.perms, rolePerms.loadedAt = perms, time.Now()
+	rolePerms.Unlock()
+	return perms, nil
+}
+
+// ReloadRolePermissions 角色的权限修改后调用，下一次检查权限时重新加载
+func ReloadRolePermissions() {
+	rolePerms.Lock()
+	rolePerms.loadedAt = time.Time{}
+	rolePerms.Unlock()
+}
+
+// 当前请求用户的角色：令牌中的角色版本号是最新的就直接使用，否则重新查询
+func currentRoles(c *gin.Context) ([]string, error) {
+	claims := GetClaims(c)
+	if claims == nil {
+		return nil, nil
+	}
+	ver, err := db.RedisDb.Get(c, roleVersionKeyPrefix+strconv.FormatInt(claims.UserId, 10)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if ver == claims.RoleVersion && len(claims.Roles) > 0 {
+		return claims.Roles, nil
+	}
+	roles, _, err := loadUserRoles(c, claims.UserId)
+	return roles, err
+}
+
+// HasPermission 当前请求用户是否拥有某个权限
+func HasPermission(c *gin.Context, perm string) (bool, error) {
+	roles, err := currentRoles(c)
+	if err != nil || len(roles) == 0 {
+		return false, err
+	}
+	perms, err := getRolePerms(c)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role == RoleAdmin {
+			return true, nil
+		}
+		if _, ok := perms[role][perm]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RequirePermission 需要拥有某个权限才能访问，放在RequireAuth之后
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, err := HasPermission(c, perm)
+		if err != nil {
+			slog.ErrorContext(c, "检查权限失败", "perm", perm, "err", err)
+			response.Error(c, response.ErrDatabase)
+			c.Abort()
+			return
+		}
+		if !ok {
+			response.Error(c, response.ErrPermissionDenied)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
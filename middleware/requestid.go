@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"log/slog"
+	"regexp"
+	"xzdp/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+	CtxKeyRequestID = "requestId"
+)
+
+// 上游（网关、其他服务）传来的请求id只接受常见字符，避免日志注入
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID 沿用请求头中的X-Request-ID，没有时生成一个，写回响应头，
+// 并把请求id、路由和客户端IP放进请求的context，之后用slog.XxxContext打的日志都会带上
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(id) {
+			var err error
+			if id, err = randomToken(16); err != nil {
+				id = "unknown"
+			}
+		}
+		c.Set(CtxKeyRequestID, id)
+		c.Header(HeaderRequestID, id)
+		c.Request = c.Request.WithContext(logger.AppendCtx(c.Request.Context(),
+			slog.String(CtxKeyRequestID, id),
+			slog.String("route", c.FullPath()),
+			slog.String("ip", c.ClientIP()),
+		))
+		c.Next()
+	}
+}
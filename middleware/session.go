@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"xzdp/config"
+	"xzdp/db"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// 多设备会话管理：每次登录创建一个会话，记录设备、UA、IP、登录时间和最后活跃时间
+
+// 最后活跃时间不需要每个请求都更新，间隔超过这个值才写一次Redis
+const lastSeenInterval = time.Minute
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionDevice 登录时的设备信息
+type SessionDevice struct {
+	Device    string
+	UserAgent string
+	IP        string
+}
+
+// SessionInfo 返回给前端的会话信息
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	UserAgent string    `json:"userAgent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Current   bool      `json:"current"` // 是否是发起请求的这个会话
+}
+
+// NewSessionDevice 从请求中获取设备信息，设备名称由客户端通过 X-Device-Name 请求头传入
+func NewSessionDevice(c *gin.Context) SessionDevice {
+	device := c.GetHeader("X-Device-Name")
+	if device == "" {
+		device = "unknown"
+	}
+	return SessionDevice{
+		Device:    device,
+		UserAgent: c.GetHeader("User-Agent"),
+		IP:        c.ClientIP(),
+	}
+}
+
+func createSession(ctx context.Context, userId int64, sid string, device SessionDevice) error {
+	now := time.Now()
+	ttl := config.JwtOption.RefreshExpire
+	userKey := userSessionsKey(userId)
+	pipe := db.RedisDb.TxPipeline()
+	pipe.HSet(ctx, sessionKey(userId, sid),
+		"userId", userId,
+		"device", device.Device,
+		"userAgent", device.UserAgent,
+		"ip", device.IP,
+		"createdAt", now.Unix(),
+		"lastSeen", now.Unix(),
+	)
+	pipe.Expire(ctx, sessionKey(userId, sid), ttl)
+	pipe.ZAdd(ctx, userKey, &redis.Z{Score: float64(now.UnixNano()), Member: sid})
+	pipe.Expire(ctx, userKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// 会话数超过上限时，按登录时间从早到晚删除多出来的会话
+func evictOldSessions(ctx context.Context, userId int64) error {
+	limit := int64(config.JwtOption.MaxSessions)
+	if limit <= 0 {
+		return nil
+	}
+	userKey := userSessionsKey(userId)
+	count, err := db.RedisDb.ZCard(ctx, userKey).Result()
+	if err != nil || count <= limit {
+		return err
+	}
+	sids, err := db.RedisDb.ZRange(ctx, userKey, 0, count-limit-1).Result()
+	if err != nil {
+		return err
+	}
+	pipe := db.RedisDb.TxPipeline()
+	for _, sid := range sids {
+		deleteSession(ctx, pipe, userId, sid)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// 会话还存在时才更新最后活跃时间，否则HSET会重新创建一个已经注销的、没有过期时间的会话
+var touchSessionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HSET', KEYS[1], 'lastSeen', ARGV[1])
+end
+return 0
+`)
+
+// 更新最后活跃时间
+func touchSession(ctx context.Context, userId int64, sid string, lastSeen int64) {
+	now := time.Now()
+	if now.Sub(time.Unix(lastSeen, 0)) < lastSeenInterval {
+		return
+	}
+	touchSessionScript.Run(ctx, db.RedisDb, []string{sessionKey(userId, sid)}, now.Unix())
+}
+
+// ListSessions 列出用户所有有效的会话，按登录时间倒序
+func ListSessions(ctx context.Context, userId int64, currentSid string) ([]SessionInfo, error) {
+	userKey := userSessionsKey(userId)
+	sids, err := db.RedisDb.ZRevRange(ctx, userKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	pipe := db.RedisDb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, 0, len(sids))
+	for _, sid := range sids {
+		cmds = append(cmds, pipe.HGetAll(ctx, sessionKey(userId, sid)))
+	}
+	if _, err = pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	res := make([]SessionInfo, 0, len(sids))
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			// 会话已经过期，顺便从列表中清理
+			db.RedisDb.ZRem(ctx, userKey, sids[i])
+			continue
+		}
+		createdAt, _ := strconv.ParseInt(fields["createdAt"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(fields["lastSeen"], 10, 64)
+		res = append(res, SessionInfo{
+			ID:        sids[i],
+			Device:    fields["device"],
+			UserAgent: fields["userAgent"],
+			IP:        fields["ip"],
+			CreatedAt: time.Unix(createdAt, 0),
+			LastSeen:  time.Unix(lastSeen, 0),
+			Current:   sids[i] == currentSid,
+		})
+	}
+	return res, nil
+}
+
+// RevokeSessionById 注销用户自己的某个会话，会话不属于这个用户时返回ErrSessionNotFound
+func RevokeSessionById(ctx context.Context, userId int64, sid string) error {
+	_, err := db.RedisDb.ZScore(ctx, userSessionsKey(userId), sid).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	pipe := db.RedisDb.TxPipeline()
+	deleteSession(ctx, pipe, userId, sid)
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"xzdp/config"
+	"xzdp/db"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 令牌设计：
+// 1. access token 是短期有效的JWT，带有 jti（令牌id）和 sid（会话id）
+// 2. refresh token 是随机字符串，保存在Redis中，每次刷新都会换一个新的（旧的立即失效）
+// 3. 退出登录时把当前 access token 的 jti 加入黑名单，并删除这个会话的 refresh token
+// 4. 退出所有设备时记录一个时间点，这个时间点之前签发的 access token 全部失效
+//
+// 同一个用户的key都带有 {userId} 哈希标签，Redis集群模式下落在同一个slot，事务中才能同时操作：
+// auth:{userId}:session:{sid} -> 会话详情（Hash），refresh字段是当前的refresh token
+// auth:{userId}:sessions -> 用户所有会话的sid（ZSet，分数为登录时间）
+// auth:{userId}:refresh:{refreshToken} -> 会话信息，refresh token的格式为 {userId}.{随机串}，刷新时才能找到对应的key
+// auth:{userId}:deny:{jti} -> 已注销的access token
+// auth:{userId}:revoked_at -> 退出所有设备的时间
+
+var ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+
+func userKeyPrefix(userId int64) string {
+	return "auth:{" + strconv.FormatInt(userId, 10) + "}:"
+}
+
+func sessionKey(userId int64, sid string) string {
+	return userKeyPrefix(userId) + "session:" + sid
+}
+
+func userSessionsKey(userId int64) string {
+	return userKeyPrefix(userId) + "sessions"
+}
+
+func refreshKey(userId int64, refreshToken string) string {
+	return userKeyPrefix(userId) + "refresh:" + refreshToken
+}
+
+func denyKey(userId int64, jti string) string {
+	return userKeyPrefix(userId) + "deny:" + jti
+}
+
+func revokedAtKey(userId int64) string {
+	return userKeyPrefix(userId) + "revoked_at"
+}
+
+// 从refresh token中取出userId
+func refreshTokenUserId(refreshToken string) (int64, bool) {
+	id, _, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return 0, false
+	}
+	userId, err := strconv.ParseInt(id, 10, 64)
+	return userId, err == nil
+}
+
+// TokenPair 登录和刷新时返回给前端的令牌
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// IssueTokens 登录成功后创建一个新会话并签发令牌，会话数超过上限时踢掉最早登录的设备
+func IssueTokens(ctx context.Context, userId int64, device SessionDevice) (*TokenPair, error) {
+	sid, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	if err = createSession(ctx, userId, sid, device); err != nil {
+		return nil, err
+	}
+	tokens, err := issueTokensForSession(ctx, userId, sid)
+	if err != nil {
+		return nil, err
+	}
+	if err = evictOldSessions(ctx, userId); err != nil {
+		slog.ErrorContext(ctx, "清理超出上限的会话失败", "userId", userId, "err", err)
+	}
+	return tokens, nil
+}
+
+func issueTokensForSession(ctx context.Context, userId int64, sid string) (*TokenPair, error) {
+	roles, roleVersion, err := loadUserRoles(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := GenerateToken(userId, sid, roles, roleVersion)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken := strconv.FormatInt(userId, 10) + "." + secret
+	ttl := config.JwtOption.RefreshExpire
+	pipe := db.RedisDb.TxPipeline()
+	pipe.HSet(ctx, refreshKey(userId, refreshToken), "sid", sid)
+	pipe.Expire(ctx, refreshKey(userId, refreshToken), ttl)
+	// 每次刷新都顺延会话的有效期
+	pipe.HSet(ctx, sessionKey(userId, sid), "refresh", refreshToken)
+	pipe.Expire(ctx, sessionKey(userId, sid), ttl)
+	pipe.Expire(ctx, userSessionsKey(userId), ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// RefreshTokens 用refresh token换一对新令牌，旧的refresh token立即失效
+func RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	//1.取出并删除旧的refresh token，保证同一个refresh token只能用一次
+	userId, ok := refreshTokenUserId(refreshToken)
+	if !ok {
+		return nil, ErrRefreshTokenInvalid
+	}
+	key := refreshKey(userId, refreshToken)
+	pipe := db.RedisDb.TxPipeline()
+	get := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	session := get.Val()
+	if len(session) == 0 {
+		return nil, ErrRefreshTokenInvalid
+	}
+	//2.会话已经被注销（退出登录或者令牌被别人用过了）
+	current, err := db.RedisDb.HGet(ctx, sessionKey(userId, session["sid"]), "refresh").Result()
+	if err != nil || current != refreshToken {
+		return nil, ErrRefreshTokenInvalid
+	}
+	//3.同一个会话签发新令牌
+	return issueTokensForSession(ctx, userId, session["sid"])
+}
+
+// RevokeSession 注销当前会话：access token加入黑名单，删除refresh token
+func RevokeSession(ctx context.Context, claims *userClaims) error {
+	pipe := db.RedisDb.TxPipeline()
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 0 {
+		pipe.Set(ctx, denyKey(claims.UserId, claims.ID), 1, ttl)
+	}
+	deleteSession(ctx, pipe, claims.UserId, claims.SessionId)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RevokeAllSessions 退出所有设备：之前签发的access token全部失效，删除所有refresh token
+func RevokeAllSessions(ctx context.Context, userId int64) error {
+	sids, err := db.RedisDb.ZRange(ctx, userSessionsKey(userId), 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	pipe := db.RedisDb.TxPipeline()
+	// access token最长有效期过后，之前签发的令牌都已经过期，这条记录也就不需要了
+	pipe.Set(ctx, revokedAtKey(userId), time.Now().Unix(), config.JwtOption.Expire)
+	for _, sid := range sids {
+		deleteSession(ctx, pipe, userId, sid)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func deleteSession(ctx context.Context, pipe redis.Pipeliner, userId int64, sid string) {
+	if refreshToken, err := db.RedisDb.HGet(ctx, sessionKey(userId, sid), "refresh").Result(); err == nil {
+		pipe.Del(ctx, refreshKey(userId, refreshToken))
+	}
+	pipe.Del(ctx, sessionKey(userId, sid))
+	pipe.ZRem(ctx, userSessionsKey(userId), sid)
+}
+
+// 旧版本签发的令牌没有jti和会话，也没有签发时间，生效时间就是签发时间
+// 升级后的 JWT.Expire 内继续接受（需要在 JWT.Secret 中配置旧的密钥），只能通过退出所有设备注销，Redis出错时按已注销处理
+func isLegacyTokenRevoked(ctx context.Context, claims *userClaims) bool {
+	if claims.NotBefore == nil || time.Since(claims.NotBefore.Time) > config.JwtOption.Expire {
+		return true
+	}
+	at, err := db.RedisDb.Get(ctx, revokedAtKey(claims.UserId)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return false
+	}
+	return err != nil || claims.NotBefore.Unix() < at
+}
+
+// 检查access token是否已被注销，Redis出错时按已注销处理
+// 会话被删除后，这个会话之前刷新出来的其他access token也一起失效
+func isTokenRevoked(ctx context.Context, claims *userClaims) bool {
+	pipe := db.RedisDb.Pipeline()
+	deny := pipe.Exists(ctx, denyKey(claims.UserId, claims.ID))
+	lastSeen := pipe.HGet(ctx, sessionKey(claims.UserId, claims.SessionId), "lastSeen")
+	revokedAt := pipe.Get(ctx, revokedAtKey(claims.UserId))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return true
+	}
+	if deny.Val() > 0 {
+		return true
+	}
+	// 会话不存在（已注销、被挤下线或者过期）
+	seen, err := lastSeen.Int64()
+	if err != nil {
+		return true
+	}
+	if at, err := revokedAt.Int64(); err == nil && claims.IssuedAt != nil && claims.IssuedAt.Unix() < at {
+		return true
+	}
+	touchSession(ctx, claims.UserId, claims.SessionId, seen)
+	return false
+}
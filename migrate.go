@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"xzdp/config"
+	"xzdp/db"
+	"xzdp/db/migrate"
+)
+
+const migrateUsage = `usage: xzdp migrate <command>
+  up              执行所有未执行的迁移，数据库不存在时先创建
+  down [n]        回滚最近的n个迁移，默认1个
+  status          查看每个迁移是否已经执行
+  force <version> 人工修复dirty的数据库后，标记为已经执行到version，0表示一个都没有执行`
+
+// xzdp migrate up|down|status|force
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n%s", migrateUsage)
+	}
+	ctx := context.Background()
+	if args[0] == "up" {
+		if err := db.CreateDatabase(config.MysqlOption); err != nil {
+			return err
+		}
+	}
+	gormDB, err := db.NewMySQL(config.MysqlOption)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	switch args[0] {
+	case "up":
+		done, err := migrate.Up(ctx, sqlDB)
+		printMigrations("applied", done, err)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+		done, err := migrate.Down(ctx, sqlDB, steps)
+		printMigrations("rolled back", done, err)
+		return err
+	case "status":
+		statuses, err := migrate.GetStatus(ctx, sqlDB)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			status, appliedAt := "pending", ""
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Dirty {
+				status = "dirty"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		return w.Flush()
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version\n%s", migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrate.Force(ctx, sqlDB, version)
+	}
+	return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+}
+
+func printMigrations(action string, migrations []migrate.Migration, err error) {
+	if len(migrations) == 0 && err == nil {
+		fmt.Println("nothing to do")
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %d_%s\n", action, m.Version, m.Name)
+	}
+}
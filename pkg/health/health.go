@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 健康检查：
+// /healthz 只要进程能处理请求就返回200，用于存活探针，失败时编排系统会重启进程
+// /readyz 并发检查注册的依赖（MySQL、Redis等），都正常才返回200，用于就绪探针，失败时只会摘掉流量
+// /readyz 是公开的，响应中每项只有up/down，错误信息和耗时只记录在日志中
+// 其他包可以通过 Register 注册自己的检查
+
+// CheckFunc 检查一个依赖，返回nil表示正常
+type CheckFunc func(ctx context.Context) error
+
+// 每个检查的超时时间，探针本身一般也只等1~2秒
+const checkTimeout = time.Second
+
+const (
+	statusUp           = "up"
+	statusDown         = "down"
+	statusReady        = "ready"
+	statusNotReady     = "not_ready"
+	statusShuttingDown = "shutting_down"
+)
+
+var (
+	mu           sync.RWMutex
+	checks       = map[string]CheckFunc{}
+	shuttingDown atomic.Bool
+)
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"` //检查名: up/down
+}
+
+// Register 注册一个就绪检查，同名的检查会被覆盖
+func Register(name string, check CheckFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check
+}
+
+// SetShuttingDown 开始优雅退出，之后 /readyz 一直返回503
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// GET /healthz
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": statusUp})
+}
+
+// GET /readyz
+func Readiness(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	if shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, readiness{Status: statusShuttingDown, Checks: map[string]string{}})
+		return
+	}
+	res := runChecks(c.Request.Context())
+	if res.Status != statusReady {
+		c.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// 并发执行所有检查，总耗时不超过checkTimeout
+func runChecks(ctx context.Context) readiness {
+	mu.RLock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	funcs := make([]CheckFunc, len(names))
+	for i, name := range names {
+		funcs[i] = checks[name]
+	}
+	mu.RUnlock()
+
+	results := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i := range funcs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, names[i], funcs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	res := readiness{Status: statusReady, Checks: make(map[string]string, len(names))}
+	for i, name := range names {
+		res.Checks[name] = statusUp
+		if results[i] != nil {
+			res.Checks[name] = statusDown
+			res.Status = statusNotReady
+		}
+	}
+	return res
+}
+
+// 失败时把错误和耗时记录到日志，不返回给调用方
+func runCheck(ctx context.Context, name string, check CheckFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	start := time.Now()
+	err := check(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "就绪检查失败", "check", name, "latency", time.Since(start), "err", err)
+	}
+	return err
+}
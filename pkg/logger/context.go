@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 用slog.InfoContext等带context的方法打日志时，自动加上 AppendCtx 放进context的字段（请求id、用户id、路由、IP），
+// 以及当前请求的traceId和spanId，方便把同一个请求的日志和链路对应起来
+type contextHandler struct {
+	slog.Handler
+}
+
+type ctxAttrsKey struct{}
+
+// AppendCtx 返回带有日志字段的context，之后用这个context打的日志都会带上这些字段
+func AppendCtx(ctx context.Context, attrs ...slog.Attr) context.Context {
+	old, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(old)+len(attrs))
+	merged = append(merged, old...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxAttrsKey{}, merged)
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(ctxAttrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("traceId", sc.TraceID().String()), slog.String("spanId", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -2,7 +2,9 @@ package logger
 
 import (
 	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"log/slog"
+	"os"
 	"strings"
 )
 
@@ -29,6 +31,7 @@ type LogSetting struct {
 	MaxSize    int
 	MaxBackups int
 	MaxAge     int
+	Console    bool //同时输出到控制台，方便本地开发
 }
 
 var LogLevel = new(slog.LevelVar)
@@ -45,10 +48,14 @@ func InitLogger(logConfig *LogSetting) {
 	LogLevel.Set(GetLogLevel(logConfig.Level)) //这样就可以在运行时更新日志等级
 
 	//使用json格式
-	logger := slog.New(slog.NewJSONHandler(&log, &slog.HandlerOptions{
+	var w io.Writer = &log
+	if logConfig.Console {
+		w = io.MultiWriter(&log, os.Stdout)
+	}
+	logger := slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{
 		AddSource: true,
 		Level:     LogLevel,
-	}))
+	})})
 
 	slog.SetDefault(logger)
 }
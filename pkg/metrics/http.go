@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+	"xzdp/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, status and business code.",
+	}, []string{"method", "route", "status", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Middleware 统计每个路由的请求数和耗时，业务码来自 response 包写入上下文的值
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// 没有匹配到路由时FullPath为空，统一归到一个标签，避免扫描器制造大量的标签值
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		code := "none"
+		if bizCode, ok := c.Get(response.CtxKeyBizCode); ok {
+			code = strconv.Itoa(bizCode.(int))
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status()), code).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
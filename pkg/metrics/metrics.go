@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus指标，统一使用 xzdp_ 前缀，通过 GET /metrics 暴露
+// 标签的取值必须是有限的：路由用注册时的模板（/api/shop/:id），缓存用key前缀，不能带id
+const namespace = "xzdp"
+
+var (
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Redis cache lookups by key prefix and result (hit or miss).",
+	}, []string{"prefix", "result"})
+
+	seckillEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "seckill_total",
+		Help:      "Seckill voucher requests by outcome.",
+	}, []string{"result"})
+)
+
+// 秒杀的结果
+const (
+	SeckillAttempt   = "attempt"   // 收到秒杀请求
+	SeckillSoldOut   = "sold_out"  // 库存不足
+	SeckillDuplicate = "duplicate" // 已经抢过
+	SeckillSuccess   = "success"   // 下单成功
+	SeckillRollback  = "rollback"  // Redis预扣成功但数据库事务失败，回滚了Redis库存
+)
+
+// CacheLookup 记录一次缓存查询，prefix是不带id的key前缀
+func CacheLookup(prefix string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(prefix, result).Inc()
+}
+
+// Seckill 记录一次秒杀事件
+func Seckill(result string) {
+	seckillEvents.WithLabelValues(result).Inc()
+}
+
+// GET /metrics
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
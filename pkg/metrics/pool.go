@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Redis连接池指标，抓取时从 PoolStats 读取
+type redisPoolCollector struct {
+	client redis.UniversalClient
+
+	hits, misses, timeouts  *prometheus.Desc
+	total, idle, staleConns *prometheus.Desc
+}
+
+// RegisterRedisPool 注册Redis连接池指标，哨兵和集群模式下是所有节点的合计
+func RegisterRedisPool(client redis.UniversalClient) error {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+	}
+	return prometheus.Register(&redisPoolCollector{
+		client:     client,
+		hits:       desc("hits_total", "Times a free connection was found in the pool."),
+		misses:     desc("misses_total", "Times a free connection was not found in the pool."),
+		timeouts:   desc("timeouts_total", "Times a wait for a connection timed out."),
+		total:      desc("connections", "Total connections in the pool."),
+		idle:       desc("idle_connections", "Idle connections in the pool."),
+		staleConns: desc("stale_connections_total", "Stale connections removed from the pool."),
+	})
+}
+
+func (r *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.hits
+	ch <- r.misses
+	ch <- r.timeouts
+	ch <- r.total
+	ch <- r.idle
+	ch <- r.staleConns
+}
+
+func (r *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := r.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(r.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(r.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(r.timeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(r.total, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(r.idle, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(r.staleConns, prometheus.CounterValue, float64(s.StaleConns))
+}
+
+// RegisterDBPool 注册MySQL连接池指标（go_sql_* 系列）
+func RegisterDBPool(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,177 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OAuth2授权码 + PKCE 客户端，身份提供方支持OIDC时用id_token中的sub作为用户的唯一标识
+// 1. AuthCodeURL 生成跳转到身份提供方的地址，state、nonce和code_verifier由调用方保存
+// 2. 身份提供方回调后用 Exchange 把授权码换成令牌
+// 3. VerifyIDToken 用身份提供方的JWKS验证id_token的签名、iss、aud、exp和nonce
+
+// 身份提供方的客户端参数，所有地址都可以配置，测试时可以指向 oidctest.Server
+type Config struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	Issuer       string
+	RedirectURL  string //身份提供方回调的地址，必须与在身份提供方登记的一致
+	Scopes       []string
+}
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id_token")
+	ErrNonceMismatch  = errors.New("id_token nonce mismatch")
+)
+
+type Provider struct {
+	setting Config
+	client  *http.Client
+	keys    *keyCache
+}
+
+// TokenResponse 令牌接口的响应
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+// IDTokenClaims id_token中用到的字段
+type IDTokenClaims struct {
+	Nonce string `json:"nonce"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+func NewProvider(setting Config) *Provider {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return &Provider{
+		setting: setting,
+		client:  client,
+		keys:    newKeyCache(setting.JWKSURL, client),
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.setting.Name
+}
+
+// RandomString 生成state、nonce和code_verifier
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge PKCE的S256 code_challenge
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL 跳转到身份提供方的授权地址
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.setting.ClientID},
+		"redirect_uri":          {p.setting.RedirectURL},
+		"scope":                 {strings.Join(p.setting.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.setting.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.setting.AuthURL + sep + v.Encode()
+}
+
+// Exchange 用授权码换令牌
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*TokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.setting.RedirectURL},
+		"client_id":     {p.setting.ClientID},
+		"code_verifier": {verifier},
+	}
+	if p.setting.ClientSecret != "" {
+		form.Set("client_secret", p.setting.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.setting.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	var token TokenResponse
+	if err = json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %s: %s %s", resp.Status, token.Error, token.ErrorDesc)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token", ErrInvalidIDToken)
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 验证id_token并返回其中的信息
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*IDTokenClaims, error) {
+	claims := &IDTokenClaims{}
+	opts := []jwt.ParserOption{
+		jwt.WithAudience(p.setting.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(p.setting.Issuer),
+	}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.get(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+	return claims, nil
+}
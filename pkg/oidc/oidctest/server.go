@@ -0,0 +1,195 @@
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+	"xzdp/pkg/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 进程内的OIDC身份提供方，用于本地开发和测试：
+//
+//	srv := oidctest.NewServer("client-id", "user-1")
+//	defer srv.Close()
+//	provider := oidc.NewProvider(srv.Config("mock", "http://localhost:8081/api/oauth/mock/callback"))
+//
+// 授权接口不需要登录，直接带着授权码回调redirect_uri；令牌接口会校验client_id、redirect_uri和PKCE
+
+const keyId = "oidctest"
+
+type authRequest struct {
+	clientID    string
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+type Server struct {
+	*httptest.Server
+	clientID string
+	key      *rsa.PrivateKey
+
+	mu       sync.Mutex
+	subject  string
+	email    string
+	audience string //为空时使用client_id
+	codes    map[string]authRequest
+}
+
+func NewServer(clientID string, subject string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{clientID: clientID, key: key, subject: subject, codes: make(map[string]authRequest)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Config 指向这个服务器的身份提供方参数
+func (s *Server) Config(name string, redirectURL string) oidc.Config {
+	return oidc.Config{
+		Name:        name,
+		ClientID:    s.clientID,
+		AuthURL:     s.URL + "/authorize",
+		TokenURL:    s.URL + "/token",
+		JWKSURL:     s.URL + "/jwks",
+		Issuer:      s.URL,
+		RedirectURL: redirectURL,
+		Scopes:      []string{"openid", "email"},
+	}
+}
+
+// SetUser 修改之后登录的用户
+func (s *Server) SetUser(subject, email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subject, s.email = subject, email
+}
+
+// SetAudience 修改之后签发的id_token的aud，用于测试客户端拒绝发给其他应用的令牌，为空时恢复为client_id
+func (s *Server) SetAudience(aud string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audience = aud
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.codes[code] = authRequest{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	s.mu.Unlock()
+	v := redirect.Query()
+	v.Set("code", code)
+	v.Set("state", q.Get("state"))
+	redirect.RawQuery = v.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	//授权码只能使用一次
+	s.mu.Lock()
+	req, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	subject, email, aud := s.subject, s.email, s.audience
+	s.mu.Unlock()
+	if !ok || req.clientID != r.PostForm.Get("client_id") || req.redirectURI != r.PostForm.Get("redirect_uri") ||
+		oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != req.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if aud == "" {
+		aud = req.clientID
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, oidc.IDTokenClaims{
+		Nonce: req.nonce,
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.URL,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{aud},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	})
+	token.Header["kid"] = keyId
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: "mock-access-token",
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   300,
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": keyId,
+		"alg": "RS256",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
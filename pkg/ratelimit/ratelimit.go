@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 基于Redis ZSet的滑动窗口限流，每次通过时记录一个时间戳，同一个key可以同时配置多个窗口（比如每分钟1次、每小时5次）
+
+// Window 在Duration时间内最多允许Limit次
+type Window struct {
+	Duration time.Duration
+	Limit    int64
+}
+
+// KEYS[1] 记录时间戳的ZSet
+// ARGV[1] 当前时间（毫秒），ARGV[2] 本次的成员，ARGV[3] 最大窗口（毫秒），之后每两个参数是一个窗口的时长（毫秒）和次数
+// 返回0表示通过，否则返回还需要等待的毫秒数
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local maxWindow = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - maxWindow)
+local wait = 0
+for i = 4, #ARGV, 2 do
+    local window = tonumber(ARGV[i])
+    local limit = tonumber(ARGV[i + 1])
+    local count = redis.call('ZCOUNT', key, now - window + 1, '+inf')
+    if count >= limit then
+        local oldest = redis.call('ZRANGEBYSCORE', key, now - window + 1, '+inf', 'WITHSCORES', 'LIMIT', count - limit, 1)
+        local w = tonumber(oldest[2]) + window - now
+        if w > wait then
+            wait = w
+        end
+    end
+end
+if wait > 0 then
+    return wait
+end
+redis.call('ZADD', key, now, ARGV[2])
+redis.call('PEXPIRE', key, maxWindow)
+return 0
+`)
+
+// Allow 检查key是否还能通过，通过时计入一次；不通过时返回需要等待的时间
+func Allow(ctx context.Context, rdb redis.Scripter, key string, windows []Window) (bool, time.Duration, error) {
+	if len(windows) == 0 {
+		return true, 0, nil
+	}
+	now := time.Now()
+	var maxWindow time.Duration
+	args := make([]any, 3, 3+len(windows)*2)
+	for _, w := range windows {
+		if w.Duration > maxWindow {
+			maxWindow = w.Duration
+		}
+		args = append(args, w.Duration.Milliseconds(), w.Limit)
+	}
+	args[0] = now.UnixMilli()
+	// 同一毫秒内可能有多个请求，成员加上纳秒避免互相覆盖
+	args[1] = strconv.FormatInt(now.UnixNano(), 10)
+	args[2] = maxWindow.Milliseconds()
+	wait, err := slidingWindowScript.Run(ctx, rdb, []string{key}, args...).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
@@ -92,6 +92,14 @@ var codes = map[int]*ErrorMeta{
 	ErrInvalidYaml:       register(http.StatusInternalServerError, "数据不是有效的YAML"),
 	ErrEncodingYaml:      register(http.StatusInternalServerError, "YAML数据无法编码"),
 	ErrDecodingYaml:      register(http.StatusInternalServerError, "YAML数据无法解码"),
+	//用户模块
+	ErrAlreadySigned:       register(http.StatusBadRequest, "已经签到过了"),
+	ErrBackupQuotaExceeded: register(http.StatusBadRequest, "本月补签次数已用完"),
+	ErrAccountLocked:       register(http.StatusForbidden, "密码错误次数过多，账号已被临时锁定"),
+	//限流
+	ErrTooManyRequests:      register(http.StatusTooManyRequests, "操作过于频繁，请稍后再试"),
+	ErrTemporarilyBanned:    register(http.StatusForbidden, "操作过于频繁，已被暂时禁止"),
+	ErrCodeAttemptsExceeded: register(http.StatusBadRequest, "验证码错误次数过多，请重新获取"),
 }
 
 type BusinessError struct {
@@ -131,3 +139,10 @@ func WrapBusinessError(code int, originalErr error, customMessage string) *Busin
 	bizErr.Err = originalErr
 	return bizErr
 }
+
+// 用户模块错误
+const (
+	ErrAlreadySigned int = iota + 110001
+	ErrBackupQuotaExceeded
+	ErrAccountLocked
+)
@@ -15,6 +15,7 @@ var allowedHTTPStatus = map[int]struct{}{
 	http.StatusForbidden:        {}, //禁止访问
 	http.StatusNotFound:         {}, //资源未找到
 	http.StatusMethodNotAllowed: {}, //方法不允许
+	http.StatusTooManyRequests:  {}, //请求过于频繁
 	// 5xx 服务器错误
 	http.StatusInternalServerError: {}, //服务器内部错误
 	http.StatusServiceUnavailable:  {}, //服务不可用
@@ -30,6 +31,7 @@ var allowedHTTPStatus = map[int]struct{}{
 // 10	1	通用 - 数据库类错误
 // 10	2	通用 - 认证授权类错误
 // 10	3	通用 - 加解码类错误
+// 10	4	通用 - 限流类错误
 // 11	0	其他服务 - 用户模块错误
 // 11	1	其他服务  - 密钥模块错误
 // 11	2	其他服务  - 策略模块错误
@@ -77,3 +79,10 @@ const (
 	ErrEncodingYaml
 	ErrDecodingYaml
 )
+
+// 限流类错误
+const (
+	ErrTooManyRequests int = iota + 100401
+	ErrTemporarilyBanned
+	ErrCodeAttemptsExceeded
+)
@@ -16,13 +16,19 @@ type Response struct {
 	ErrorMsg string `json:"errorMsg,omitempty"`
 }
 
+// CtxKeyBizCode 写入响应的业务码保存在上下文中，供统计请求的中间件使用
+const CtxKeyBizCode = "bizCode"
+
 // WriteResponse used to write an error and JSON data into response.
 func writeResponse(c *gin.Context, bizCode int, message string, data any) {
-	coder, ok := codes[bizCode]
+	meta, ok := codes[bizCode]
 	if !ok {
-		coder = codes[ErrUnknown]
+		meta = codes[ErrUnknown]
 	}
+	c.Set(CtxKeyBizCode, bizCode)
 
+	//codes中的ErrorMeta是共享的，复制一份再替换消息，否则自定义消息会影响其他请求
+	coder := *meta
 	if message != "" {
 		coder.Message = message
 	}
@@ -0,0 +1,81 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// 本地开发用，短信内容只打到日志里
+type logSender struct{}
+
+func (logSender) Send(ctx context.Context, phone string, content string) error {
+	slog.Info("发送短信", "phone", phone, "content", content)
+	return nil
+}
+
+// 本地开发和测试用，短信追加写入文件，每行一条
+type fileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *fileSender) Send(ctx context.Context, phone string, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.DateTime), phone, content)
+	return err
+}
+
+// 通用短信网关：POST {"phone": "...", "content": "..."}，返回2xx表示发送成功
+type httpSender struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func newHTTPSender(url, apiKey string, timeout time.Duration) *httpSender {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &httpSender{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *httpSender) Send(ctx context.Context, phone string, content string) error {
+	body, err := json.Marshal(map[string]string{"phone": phone, "content": content})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway returned %s", resp.Status)
+	}
+	return nil
+}
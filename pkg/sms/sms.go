@@ -0,0 +1,77 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 初始化后，发送短信直接使用 sms.Send(ctx, phone, "login", map[string]string{"code": "1234"})
+
+// SMSSender 短信发送接口，content是已经渲染好的短信内容
+type SMSSender interface {
+	Send(ctx context.Context, phone string, content string) error
+}
+
+var (
+	ErrUnknownTemplate = errors.New("unknown sms template")
+	ErrUnknownProvider = errors.New("unknown sms provider")
+)
+
+var (
+	sender    SMSSender = logSender{}
+	templates map[string]string
+)
+
+// InitSMS 设置短信发送器和短信模板，模板中的{name}会被替换为对应的参数
+func InitSMS(s SMSSender, tpls map[string]string) {
+	sender = s
+	// viper读取map时key会转成小写
+	templates = make(map[string]string, len(tpls))
+	for name, tpl := range tpls {
+		templates[strings.ToLower(name)] = tpl
+	}
+}
+
+// NewSender 创建短信发送器
+// provider为log时只打印日志；为file时追加写入file；为http时调用url的短信网关，apiKey放在Authorization请求头中
+func NewSender(provider, file, url, apiKey string, timeout time.Duration) (SMSSender, error) {
+	switch strings.ToLower(provider) {
+	case "", "log":
+		return logSender{}, nil
+	case "file":
+		return &fileSender{path: file}, nil
+	case "http":
+		return newHTTPSender(url, apiKey, timeout), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+}
+
+// SetSender 替换短信发送器，方便在测试中记录发送的短信
+func SetSender(s SMSSender) {
+	sender = s
+}
+
+// Render 渲染短信模板
+func Render(template string, params map[string]string) (string, error) {
+	tpl, ok := templates[strings.ToLower(template)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTemplate, template)
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(tpl), nil
+}
+
+// Send 用模板渲染短信内容并发送
+func Send(ctx context.Context, phone string, template string, params map[string]string) error {
+	content, err := Render(template, params)
+	if err != nil {
+		return err
+	}
+	return sender.Send(ctx, phone, content)
+}
@@ -3,12 +3,21 @@ package router
 import (
 	"net/http"
 	"path/filepath"
+	"xzdp/handle/Account"
+	"xzdp/handle/Admin"
+	"xzdp/handle/Blog"
+	"xzdp/handle/Credit"
+	"xzdp/handle/OAuth"
 	"xzdp/handle/Order"
 	"xzdp/handle/Shop"
+	"xzdp/handle/Sign"
 	"xzdp/handle/User"
 	"xzdp/handle/Voucher"
 	"xzdp/middleware"
+	"xzdp/pkg/health"
+	"xzdp/pkg/metrics"
 	"xzdp/pkg/response"
+	"xzdp/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,9 +26,21 @@ func HandleNotFound(c *gin.Context) {
 	response.Error(c, response.ErrNotFound, "route not found")
 }
 
+// NewMetricsRouter Prometheus指标，监听 Server.MetricsAddr，与业务接口分开，不对公网开放
+func NewMetricsRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.GET("/metrics", metrics.Handler())
+	return r
+}
+
 func NewRouter() *gin.Engine {
 	//gin.SetMode(gin.ReleaseMode) //将项目设为开发模式，减少输出的log，提高性能
-	r := gin.Default()
+	//不用gin.Default()自带的文本日志，访问日志由AccessLog用slog输出
+	r := gin.New()
+	//handler直接把*gin.Context当作context传给数据库和Redis，需要从c.Request.Context()中取出span和日志字段
+	r.ContextWithFallback = true
+	r.Use(gin.Recovery(), middleware.RequestID(), tracing.Middleware(), metrics.Middleware(), middleware.AccessLog())
 
 	// 配置静态文件服务 - 提供静态资源（CSS、JS、图片等）
 	staticDir := filepath.Join("nginx-1.18.0", "html", "hmdp")
@@ -41,6 +62,13 @@ func NewRouter() *gin.Engine {
 		c.File(indexPath)
 	})
 
+	// 存活和就绪探针
+	r.GET("/healthz", health.Liveness)
+	r.GET("/readyz", health.Readiness)
+
+	// 公开JWT公钥，其他服务用来验证我们签发的令牌
+	r.GET("/.well-known/jwks.json", middleware.JWKS)
+
 	// Use为当前路由组中的所有路由绑定中间件，使得该组内的所有请求在到达具体处理函数前，都会先经过这些中间件的处理
 	public := r.Group("/api")
 	public.Use(middleware.OptionalJWT())
@@ -49,17 +77,18 @@ func NewRouter() *gin.Engine {
 		public.GET("/shop/:id", Shop.QueryShopById)
 		public.GET("/shop-type/list", Shop.QueryShopTypeList)
 		public.GET("/shop/of/type", Shop.GetShopByTypeId)
-		public.POST("/shop/add", Shop.AddShop)
-		public.DELETE("/shop/delete/:shopId", Shop.DelShop)
-		public.PUT("/shop/update", Shop.UpdateShop)
 		//用户相关
 		public.POST("/user/code", User.SendVerifyCode)
 		public.POST("/user/login", User.Login)
+		public.POST("/user/refresh", User.RefreshToken)
+		public.POST("/user/password/reset", User.ResetPassword)
+		public.GET("/oauth/:provider/login", OAuth.Login)
+		public.GET("/oauth/:provider/callback", OAuth.Callback)
+		public.POST("/oauth/bind", OAuth.BindPhone)
 		//博客相关
-		public.GET("/blog/hot", Shop.GetHotBlog)
-		//优惠券相关
-		public.POST("voucher/add/", Voucher.AddVoucher)
-		public.POST("voucher-order/seckill/:id", Order.SeckillVouchers)
+		public.GET("/blog/hot", Blog.GetHotBlog)
+		public.GET("/blog/:id", Blog.GetBlogById)
+		public.GET("/blog/of/user", Blog.GetBlogsOfUser)
 	}
 	auth := r.Group("/api")
 	auth.Use(middleware.OptionalJWT(), middleware.RequireAuth())
@@ -69,10 +98,54 @@ func NewRouter() *gin.Engine {
 		////每次点击个人信息页时获取用户信息
 		auth.GET("/user/info/:userId", User.GetUserInfoById)
 		auth.POST("/user/logout", User.Logout)
+		auth.POST("/user/logout/all", User.LogoutAll)
+		auth.GET("/user/sessions", User.GetSessions)
+		auth.DELETE("/user/sessions/:sid", User.RevokeSession)
+		auth.PUT("/user/password", User.ChangePassword)
 		auth.PUT("user/nickname", User.EditNickname)
+		auth.PUT("/user/info", User.EditProfile)
+		auth.POST("/user/icon", User.UploadIcon)
+		auth.GET("/user/export", Account.ExportData)
+		auth.POST("/user/deactivate", Account.RequestDeletion)
+		//博客相关
+		auth.PUT("/blog/like/:id", Blog.LikeBlog)
+		auth.POST("/blog/comments", Blog.AddComment)
+		auth.GET("/blog/of/me", Blog.GetMyBlogs)
+		auth.POST("/blog", Blog.PublishBlog)
+		auth.PUT("/blog", Blog.UpdateBlog)
+		auth.DELETE("/blog/:id", Blog.DeleteBlog)
+		//签到相关
+		auth.POST("/sign", Sign.SignIn)
+		auth.POST("/sign/backup", Sign.BackupSign)
+		auth.GET("/sign/stat", Sign.GetSignStat)
+		//积分相关
+		auth.GET("/credit", Credit.GetMyCredit)
+		auth.GET("/credit/logs", Credit.GetCreditLogs)
 		//优惠券相关
 		auth.GET("/voucher/list/:shopId", Voucher.GetVouchersByShopId)
-		// auth.POST("voucher-order/seckill/:id", Order.SeckillVouchers)
+		auth.POST("voucher-order/seckill/:id", Order.SeckillVouchers)
+	}
+	// 需要权限的接口，角色和权限在 tb_role、tb_permission 中配置
+	shopWrite := auth.Group("", middleware.RequirePermission(middleware.PermShopWrite))
+	{
+		shopWrite.POST("/shop/add", Shop.AddShop)
+		shopWrite.DELETE("/shop/delete/:shopId", Shop.DelShop)
+		shopWrite.PUT("/shop/update", Shop.UpdateShop)
+	}
+	voucherWrite := auth.Group("", middleware.RequirePermission(middleware.PermVoucherWrite))
+	{
+		voucherWrite.POST("voucher/add/", Voucher.AddVoucher)
+	}
+	admin := auth.Group("/admin", middleware.RequirePermission(middleware.PermUserRole))
+	{
+		admin.GET("/roles", Admin.ListRoles)
+		admin.GET("/users/:userId/roles", Admin.GetUserRoles)
+		admin.POST("/users/:userId/roles", Admin.GrantRole)
+		admin.DELETE("/users/:userId/roles/:role", Admin.RevokeRole)
+	}
+	adminConfig := auth.Group("/admin", middleware.RequirePermission(middleware.PermConfigRead))
+	{
+		adminConfig.GET("/config", Admin.GetConfig)
 	}
 	r.StaticFile("/index.html", filepath.Join(staticDir, "index.html"))
 	r.StaticFile("/login.html", filepath.Join(staticDir, "login.html"))
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"xzdp/config"
+	"xzdp/db"
+	"xzdp/db/migrate"
+	"xzdp/db/seed"
+	"xzdp/handle/Blog"
+	"xzdp/middleware"
+
+	"github.com/spf13/pflag"
+)
+
+// xzdp [-c config.yaml] seed [--seed n] [--users n] ...
+func runSeed(args []string) error {
+	//1.解析seed子命令自己的参数
+	var seedOpts seed.Options
+	var tokenFile string
+	flags := pflag.NewFlagSet("seed", pflag.ExitOnError)
+	flags.Uint64Var(&seedOpts.Seed, "seed", 1, "random seed, the same seed generates the same data")
+	flags.IntVar(&seedOpts.Users, "users", 100, "number of users")
+	flags.IntVar(&seedOpts.FollowsPerUser, "follows-per-user", 5, "number of users each user follows")
+	flags.IntVar(&seedOpts.Shops, "shops", 50, "number of shops, spread across all shop types")
+	flags.IntVar(&seedOpts.VouchersPerShop, "vouchers-per-shop", 2, "number of ordinary vouchers per shop")
+	flags.IntVar(&seedOpts.SeckillVouchers, "seckill-vouchers", 5, "number of seckill vouchers")
+	flags.IntVar(&seedOpts.SeckillStock, "seckill-stock", 100, "stock of each seckill voucher")
+	flags.IntVar(&seedOpts.Blogs, "blogs", 200, "number of blogs")
+	flags.IntVar(&seedOpts.BatchSize, "batch-size", 500, "rows per insert statement")
+	flags.StringVar(&tokenFile, "token-file", "tokens.csv", "write a login token for every user to this csv file, empty to skip")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	ctx := context.Background()
+	//2.初始化JWT密钥、数据库和Redis，表结构必须是最新的
+	if err := middleware.InitKeySet(config.JwtOption); err != nil {
+		return err
+	}
+	var err error
+	if db.DBEngine, err = db.NewMySQL(config.MysqlOption); err != nil {
+		return err
+	}
+	defer db.Close()
+	sqlDB, err := db.DBEngine.DB()
+	if err != nil {
+		return err
+	}
+	if err = migrate.Check(ctx, sqlDB); err != nil {
+		return fmt.Errorf("%w, run `xzdp migrate up` first", err)
+	}
+	if db.RedisDb, err = db.NewRedisClient(config.RedisOption); err != nil {
+		return err
+	}
+	//3.生成数据
+	res, err := seed.Run(ctx, seedOpts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("users: %d, follows: %d, shops: %d, vouchers: %d, seckill vouchers: %d, blogs: %d\n",
+		len(res.Users), res.Follows, res.Shops, res.Vouchers, len(res.SeckillVouchers), res.Blogs)
+	if len(res.SeckillVouchers) > 0 {
+		fmt.Printf("seckill voucher ids: %d ... %d\n", res.SeckillVouchers[0], res.SeckillVouchers[len(res.SeckillVouchers)-1])
+	}
+	//4.新博客需要进入热门排行榜
+	if res.Blogs > 0 {
+		if err = Blog.ResetHotRank(ctx); err != nil {
+			return err
+		}
+	}
+	//5.给每个用户签发令牌，压测工具从文件中读取
+	if tokenFile == "" || len(res.Users) == 0 {
+		return nil
+	}
+	if err = writeTokens(ctx, tokenFile, res.Users); err != nil {
+		return err
+	}
+	fmt.Printf("tokens written to %s\n", tokenFile)
+	return nil
+}
+
+func writeTokens(ctx context.Context, path string, users []seed.User) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err = w.Write([]string{"userId", "phone", "token", "refreshToken"}); err != nil {
+		return err
+	}
+	for _, u := range users {
+		tokens, err := middleware.IssueTokens(ctx, int64(u.ID), middleware.SessionDevice{Device: "seed"})
+		if err != nil {
+			return fmt.Errorf("issue token for user %d: %w", u.ID, err)
+		}
+		err = w.Write([]string{strconv.FormatUint(u.ID, 10), u.Phone, tokens.AccessToken, tokens.RefreshToken})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}